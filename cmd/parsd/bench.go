@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/luxfi/crypto/mldsa"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/messaging"
+)
+
+// benchPayloadSize is the plaintext size used for the message benchmark
+const benchPayloadSize = 1024
+
+// benchTTL is the TTL of benchmarked messages, within every network's bounds
+const benchTTL = 24 * 60 * 60
+
+// benchResult is the throughput of one benchmarked operation
+type benchResult struct {
+	Name    string
+	Ops     int
+	Elapsed time.Duration
+}
+
+// OpsPerSec returns the measured throughput
+func (r benchResult) OpsPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Ops) / r.Elapsed.Seconds()
+}
+
+// runBench implements `parsd bench crypto`
+func runBench(args []string) int {
+	if len(args) == 0 || args[0] != "crypto" {
+		fmt.Fprintln(os.Stderr, "usage: parsd bench crypto [--kem scheme] [--duration d]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("bench crypto", flag.ContinueOnError)
+	kemScheme := fs.String("kem", messaging.DefaultKEMScheme, "KEM scheme to benchmark")
+	duration := fs.Duration("duration", time.Second, "Time spent on each operation")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	kem, err := messaging.LookupKEM(*kemScheme)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	results, err := benchCrypto(kem, *duration)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// parsd links only the pure Go backends; GPU builds of luxfi/crypto
+	// require cgo and the native lux-gpu library, so there is no --gpu
+	printBench(os.Stdout, "cpu", kem.Name(), results)
+	return 0
+}
+
+// benchCrypto measures each messaging crypto operation for duration
+func benchCrypto(kem messaging.KEM, duration time.Duration) ([]benchResult, error) {
+	dsa, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA65)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DSA key: %w", err)
+	}
+	kemPub, kemPriv, err := kem.GenerateKeyPair(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate KEM key: %w", err)
+	}
+
+	msg := make([]byte, benchPayloadSize)
+	sig, err := dsa.Sign(rand.Reader, msg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	ct, _, err := kem.Encapsulate(kemPub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encapsulate: %w", err)
+	}
+	send, err := benchMessenger(kem, kemPub, dsa.PublicKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer send.messenger.Stop()
+
+	ops := []struct {
+		name string
+		fn   func() error
+	}{
+		{"keygen", func() error {
+			if _, _, err := kem.GenerateKeyPair(rand.Reader); err != nil {
+				return err
+			}
+			_, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA65)
+			return err
+		}},
+		{"sign", func() error {
+			_, err := dsa.Sign(rand.Reader, msg, nil)
+			return err
+		}},
+		{"verify", func() error {
+			if !dsa.PublicKey.VerifySignature(msg, sig) {
+				return fmt.Errorf("signature did not verify")
+			}
+			return nil
+		}},
+		{"kem-encap", func() error {
+			_, _, err := kem.Encapsulate(kemPub)
+			return err
+		}},
+		{"kem-decap", func() error {
+			_, err := kem.Decapsulate(kemPriv, ct)
+			return err
+		}},
+		{"encrypt-message", func() error {
+			return send.send(msg)
+		}},
+	}
+
+	results := make([]benchResult, 0, len(ops))
+	for _, op := range ops {
+		r, err := measure(op.name, duration, op.fn)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// benchSender sends benchmark messages through a Messenger
+type benchSender struct {
+	messenger *messaging.Messenger
+	sender    string
+	recipient string
+}
+
+// benchMessenger returns a sender for the real send path, without storage:
+// compression, padding, sealing to the recipient with kem and signing
+// with a fresh node identity
+func benchMessenger(kem messaging.KEM, kemPub, dsaPub []byte) (*benchSender, error) {
+	cfg := config.Default()
+	cfg.Crypto.KEMScheme = kem.Name()
+	m, err := messaging.NewMessenger(cfg.Pars, cfg.Crypto)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := messaging.GenerateIdentity(cfg.Crypto)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := messaging.NewSoftwareSigner(id)
+	if err != nil {
+		return nil, err
+	}
+	m.SetSigner(signer)
+
+	recipient, err := messaging.DeriveSessionID(kemPub, dsaPub, messaging.SessionIDHex)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.AddContact(recipient, kemPub, dsaPub); err != nil {
+		return nil, err
+	}
+	return &benchSender{messenger: m, sender: id.SessionID, recipient: recipient}, nil
+}
+
+// send seals, signs and sends plaintext as one message
+func (b *benchSender) send(plaintext []byte) error {
+	return b.messenger.Send(context.Background(), &messaging.Message{
+		ID:          "bench",
+		SenderID:    b.sender,
+		RecipientID: b.recipient,
+		Plaintext:   plaintext,
+		TTL:         benchTTL,
+	})
+}
+
+// measure runs fn repeatedly for duration, at least once
+func measure(name string, duration time.Duration, fn func() error) (benchResult, error) {
+	start := time.Now()
+	ops := 0
+	for {
+		if err := fn(); err != nil {
+			return benchResult{}, fmt.Errorf("%s: %w", name, err)
+		}
+		ops++
+		if time.Since(start) >= duration {
+			break
+		}
+	}
+	return benchResult{Name: name, Ops: ops, Elapsed: time.Since(start)}, nil
+}
+
+func printBench(w io.Writer, backend, kemScheme string, results []benchResult) {
+	fmt.Fprintf(w, "backend: %s  kem: %s  signature: ML-DSA-65\n\n", backend, kemScheme)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "operation\tops\tops/sec")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%.0f\n", r.Name, r.Ops, r.OpsPerSec())
+	}
+	tw.Flush()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/messaging"
+)
+
+func TestBenchCrypto(t *testing.T) {
+	kem, err := messaging.LookupKEM(messaging.DefaultKEMScheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := benchCrypto(kem, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("bench: %v", err)
+	}
+
+	want := []string{"keygen", "sign", "verify", "kem-encap", "kem-decap", "encrypt-message"}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(results))
+	}
+	for i, r := range results {
+		if r.Name != want[i] {
+			t.Errorf("result %d: expected %s, got %s", i, want[i], r.Name)
+		}
+		if r.Ops == 0 || r.OpsPerSec() <= 0 {
+			t.Errorf("%s: expected non-zero throughput, got %+v", r.Name, r)
+		}
+	}
+
+	var out bytes.Buffer
+	printBench(&out, "cpu", kem.Name(), results)
+	for _, name := range want {
+		if !strings.Contains(out.String(), name) {
+			t.Errorf("expected %s in report:\n%s", name, out.String())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/parsdao/node/config"
+)
+
+// runCheckConfig implements `parsd check-config`: everything parsd checks
+// before launching luxd, without launching it
+func runCheckConfig(args []string) int {
+	fs := flag.NewFlagSet("check-config", flag.ContinueOnError)
+	cfgPath := fs.String("config", "", "Path to a JSON node config file")
+	keyFile := fs.String("verify-config", "", "ML-DSA-65 public key file to check <config>.sig against")
+	dir := fs.String("data-dir", "", "Data directory (default: ~/.pars)")
+	hHost := fs.String("http-host", "", "Interface for the HTTP API")
+	hPort := fs.Int("http-port", DefaultHTTPPort, "HTTP API port")
+	sHost := fs.String("staking-host", "", "Interface for staking/P2P")
+	sPort := fs.Int("staking-port", DefaultStakingPort, "Staking/P2P port")
+	overrides := addCryptoFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	dataPath := *dir
+	if dataPath == "" {
+		dataPath, _ = defaultDataDir(os.UserHomeDir)
+	}
+
+	opts := overrides.options()
+	opts.DataDir = dataPath
+	return checkConfig(os.Stdout, *cfgPath, *keyFile, opts, filepath.Join(dataPath, "plugins"), []listenAddr{
+		{name: "http", host: *hHost, port: *hPort},
+		{name: "staking", host: *sHost, port: *sPort},
+	})
+}
+
+// checkConfig loads and validates the config with the same options parsd
+// applies, resolves the plugins and test-binds the ports, reporting each to
+// w. It returns the exit code: 0 if every check passed, 1 otherwise.
+func checkConfig(w io.Writer, cfgPath, keyFile string, opts *config.Options, pluginDir string, listeners []listenAddr) int {
+	failed := false
+	report := func(check string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Fprintf(w, "FAIL  %s: %v\n", check, err)
+			return
+		}
+		fmt.Fprintf(w, "ok    %s\n", check)
+	}
+
+	// Load validates the config
+	_, err := loadConfig(cfgPath, keyFile, opts)
+	report("config", err)
+
+	for _, r := range checkPlugins(pluginDir, vmPlugins) {
+		report("plugin "+r.Name, r.Err)
+	}
+
+	for _, l := range listeners {
+		if l.host != "" {
+			if err := validateHost(l.host); err != nil {
+				report(l.name+" host", err)
+				continue
+			}
+		}
+		report(fmt.Sprintf("%s port %d", l.name, l.port), checkPortsFree([]listenAddr{l}))
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestCheckConfig(t *testing.T) {
+	pluginDir := t.TempDir()
+	for _, p := range vmPlugins {
+		if err := os.WriteFile(filepath.Join(pluginDir, p.vmID), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listeners := []listenAddr{{name: "http", host: "127.0.0.1", port: freePort(t)}}
+
+	valid := filepath.Join(t.TempDir(), "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"evm": {"precompiles": {"fhe": "0x0800"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if code := checkConfig(&out, valid, "", nil, pluginDir, listeners); code != 0 {
+		t.Errorf("expected exit 0 for a valid config, got %d:\n%s", code, out.String())
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"evm": {"precompiles": {"fhe": "0xZZ"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if code := checkConfig(&out, invalid, "", nil, pluginDir, listeners); code == 0 {
+		t.Error("expected non-zero exit for a bad precompile address")
+	}
+	if !strings.Contains(out.String(), `FAIL  config: invalid evm.precompiles.fhe address "0xZZ"`) {
+		t.Errorf("expected the precompile error in the report, got:\n%s", out.String())
+	}
+
+	// The crypto override flags are checked as parsd would apply them
+	out.Reset()
+	if code := checkConfig(&out, valid, "", &config.Options{KEMScheme: "Kyber768"}, pluginDir, listeners); code == 0 {
+		t.Error("expected non-zero exit for an unknown --kem-scheme")
+	}
+	if !strings.Contains(out.String(), `FAIL  config: unknown KEM scheme "Kyber768"`) {
+		t.Errorf("expected the KEM error in the report, got:\n%s", out.String())
+	}
+}
+
+func TestCheckConfigPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	var out bytes.Buffer
+	code := checkConfig(&out, "", "", nil, t.TempDir(), []listenAddr{{name: "staking", host: "127.0.0.1", port: port}})
+	if code == 0 {
+		t.Error("expected non-zero exit for a busy port")
+	}
+	if !strings.Contains(out.String(), "FAIL  staking port") {
+		t.Errorf("expected the port failure in the report, got:\n%s", out.String())
+	}
+}
+
+// freePort returns a port that was free a moment ago
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// maxPluginSize bounds a downloaded plugin binary
+const maxPluginSize = 1 << 30 // 1GB
+
+// pluginChecksums pins the SHA-256 of each released plugin binary, keyed
+// by pluginAsset. Update it with every plugin release, or pin releases
+// without a rebuild through --plugin-checksums; a plugin without a pinned
+// checksum is never downloaded.
+var pluginChecksums = map[string]string{}
+
+var (
+	ErrNoPinnedChecksum = errors.New("no pinned checksum")
+	ErrChecksumMismatch = errors.New("plugin checksum mismatch")
+	ErrInvalidChecksums = errors.New("invalid plugin checksums file")
+)
+
+// pluginFetch installs plugin p at dst when it is not found locally
+type pluginFetch func(ctx context.Context, p pluginLink, dst string) error
+
+// pluginDownloader fetches plugin binaries from a release URL
+type pluginDownloader struct {
+	baseURL   string
+	goos      string
+	goarch    string
+	checksums map[string]string
+	client    *http.Client
+}
+
+// newPluginDownloader downloads host binaries from baseURL, checked
+// against checksums, keyed by pluginAsset
+func newPluginDownloader(baseURL string, checksums map[string]string) *pluginDownloader {
+	return &pluginDownloader{
+		baseURL:   baseURL,
+		goos:      runtime.GOOS,
+		goarch:    runtime.GOARCH,
+		checksums: checksums,
+		client:    &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// loadPluginChecksums returns pluginChecksums extended by the file at
+// path, in sha256sum format ("<sha256>  <asset>" per line, e.g. a release's
+// SHA256SUMS). Entries in the file replace built-in pins for the same
+// asset. An empty path returns the built-in pins.
+func loadPluginChecksums(path string) (map[string]string, error) {
+	checksums := maps.Clone(pluginChecksums)
+	if path == "" {
+		return checksums, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: %s:%d: expected \"<sha256> <asset>\"", ErrInvalidChecksums, path, i+1)
+		}
+		sum, asset := strings.ToLower(fields[0]), strings.TrimPrefix(fields[1], "*")
+		if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("%w: %s:%d: %q is not a SHA-256", ErrInvalidChecksums, path, i+1, fields[0])
+		}
+		checksums[asset] = sum
+	}
+	return checksums, nil
+}
+
+// pluginAsset names p's release binary for goos/goarch, e.g.
+// "evm-linux-amd64"
+func pluginAsset(p pluginLink, goos, goarch string) string {
+	return fmt.Sprintf("%s-%s-%s", strings.ToLower(p.name), goos, goarch)
+}
+
+// pluginURL returns where the release binary for p is downloaded from
+func pluginURL(baseURL string, p pluginLink, goos, goarch string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + pluginAsset(p, goos, goarch)
+}
+
+// fetch downloads p, verifies its SHA-256 against the pinned checksum and
+// installs it executable at dst. Nothing is left at dst on failure.
+func (d *pluginDownloader) fetch(ctx context.Context, p pluginLink, dst string) error {
+	asset := pluginAsset(p, d.goos, d.goarch)
+	want, ok := d.checksums[asset]
+	if !ok {
+		return fmt.Errorf("%w for %s", ErrNoPinnedChecksum, asset)
+	}
+
+	url := pluginURL(d.baseURL, p, d.goos, d.goarch)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), io.LimitReader(resp.Body, maxPluginSize))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", url, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != strings.ToLower(want) {
+		return fmt.Errorf("%w: %s has SHA-256 %s, expected %s", ErrChecksumMismatch, asset, got, want)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
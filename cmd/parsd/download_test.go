@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/log"
+)
+
+func TestPluginURL(t *testing.T) {
+	evm := pluginLink{name: "EVM", vmID: EVMID}
+	session := pluginLink{name: "SessionVM", vmID: SessionVMID}
+
+	tests := []struct {
+		base         string
+		p            pluginLink
+		goos, goarch string
+		want         string
+	}{
+		{"https://example.com/v1", evm, "linux", "amd64", "https://example.com/v1/evm-linux-amd64"},
+		{"https://example.com/v1/", session, "darwin", "arm64", "https://example.com/v1/sessionvm-darwin-arm64"},
+	}
+	for _, tt := range tests {
+		if got := pluginURL(tt.base, tt.p, tt.goos, tt.goarch); got != tt.want {
+			t.Errorf("%s %s/%s: expected %s, got %s", tt.p.name, tt.goos, tt.goarch, tt.want, got)
+		}
+	}
+}
+
+// testDownloader serves binary as every asset, pinned to checksum
+func testDownloader(t *testing.T, binary []byte, checksums map[string]string) (*pluginDownloader, *int) {
+	t.Helper()
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/releases/evm-linux-amd64" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(binary)
+	}))
+	t.Cleanup(srv.Close)
+
+	d := newPluginDownloader(srv.URL+"/releases", checksums)
+	d.goos, d.goarch = "linux", "amd64"
+	return d, &requests
+}
+
+func TestPluginDownload(t *testing.T) {
+	binary := []byte("#!/bin/sh\n")
+	sum := sha256.Sum256(binary)
+	evm := pluginLink{name: "EVM", vmID: EVMID}
+	ctx := context.Background()
+
+	t.Run("verified", func(t *testing.T) {
+		d, _ := testDownloader(t, binary, map[string]string{"evm-linux-amd64": hex.EncodeToString(sum[:])})
+		dst := filepath.Join(t.TempDir(), EVMID)
+		if err := d.fetch(ctx, evm, dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		fi, err := os.Stat(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm()&0111 == 0 {
+			t.Errorf("expected executable plugin, got mode %v", fi.Mode())
+		}
+		if got, _ := os.ReadFile(dst); string(got) != string(binary) {
+			t.Errorf("expected downloaded contents, got %q", got)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		d, _ := testDownloader(t, binary, map[string]string{"evm-linux-amd64": hex.EncodeToString(make([]byte, 32))})
+		dir := t.TempDir()
+		if err := d.fetch(ctx, evm, filepath.Join(dir, EVMID)); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("expected ErrChecksumMismatch, got %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("expected nothing installed, found %v", entries)
+		}
+	})
+
+	t.Run("unpinned", func(t *testing.T) {
+		d, requests := testDownloader(t, binary, map[string]string{})
+		if err := d.fetch(ctx, evm, filepath.Join(t.TempDir(), EVMID)); !errors.Is(err, ErrNoPinnedChecksum) {
+			t.Errorf("expected ErrNoPinnedChecksum, got %v", err)
+		}
+		if *requests != 0 {
+			t.Errorf("expected no download without a pinned checksum, got %d requests", *requests)
+		}
+	})
+
+	t.Run("missing asset", func(t *testing.T) {
+		d, _ := testDownloader(t, binary, map[string]string{"sessionvm-linux-amd64": hex.EncodeToString(sum[:])})
+		session := pluginLink{name: "SessionVM", vmID: SessionVMID}
+		if err := d.fetch(ctx, session, filepath.Join(t.TempDir(), SessionVMID)); err == nil {
+			t.Error("expected error for a 404")
+		}
+	})
+}
+
+func TestLinkPluginsDownloadFailureAborts(t *testing.T) {
+	pluginDir := t.TempDir()
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "evm"), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	notFound := func() (string, error) { return "", errors.New("not found") }
+	plugins := []pluginLink{
+		{name: "EVM", vmID: EVMID, find: func() (string, error) { return filepath.Join(binDir, "evm"), nil }},
+		{name: "SessionVM", vmID: SessionVMID, find: notFound},
+	}
+
+	errOffline := errors.New("offline")
+	fetch := func(ctx context.Context, p pluginLink, dst string) error { return errOffline }
+	if err := linkPlugins(context.Background(), pluginDir, plugins, false, fetch, log.NewNoOpLogger()); !errors.Is(err, errOffline) {
+		t.Fatalf("expected download error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(pluginDir); len(entries) != 0 {
+		t.Errorf("expected setup rolled back, found %v", entries)
+	}
+
+	fetch = func(ctx context.Context, p pluginLink, dst string) error { return os.WriteFile(dst, nil, 0755) }
+	if err := linkPlugins(context.Background(), pluginDir, plugins, false, fetch, log.NewNoOpLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(pluginDir, SessionVMID)); err != nil {
+		t.Errorf("expected downloaded plugin installed, got %v", err)
+	}
+}
+
+func TestLoadPluginChecksums(t *testing.T) {
+	sum := strings.Repeat("ab", sha256.Size)
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"sha256sum output", sum + "  evm-linux-amd64\n" + strings.ToUpper(sum) + " *sessionvm-linux-amd64\n",
+			map[string]string{"evm-linux-amd64": sum, "sessionvm-linux-amd64": sum}, false},
+		{"comments and blank lines", "# release v1.2.0\n\n" + sum + "  evm-darwin-arm64\n",
+			map[string]string{"evm-darwin-arm64": sum}, false},
+		{"missing asset", sum + "\n", nil, true},
+		{"short sum", "abcd  evm-linux-amd64\n", nil, true},
+		{"not hex", strings.Repeat("zz", sha256.Size) + "  evm-linux-amd64\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "SHA256SUMS")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadPluginChecksums(path)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidChecksums) {
+					t.Errorf("expected ErrInvalidChecksums, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if got, err := loadPluginChecksums(""); err != nil || !maps.Equal(got, pluginChecksums) {
+		t.Errorf("expected the built-in pins without a file, got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/parsdao/node/vm"
+)
+
+// DefaultHealthTimeout bounds each health request to luxd
+const DefaultHealthTimeout = 5 * time.Second
+
+// chainComponents names the health component of each chain alias
+var chainComponents = map[string]string{
+	CChainAlias: "evm",
+	SChainAlias: "sessionvm",
+}
+
+// runHealth implements `parsd health`: probe the chains a running node
+// tracks through luxd's health API, and report the health parsd records
+// for itself, ParsVM included
+func runHealth(args []string) int {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	host := fs.String("http-host", "127.0.0.1", "Host of the node's HTTP API")
+	port := fs.Int("http-port", DefaultHTTPPort, "Port of the node's HTTP API")
+	timeout := fs.Duration("timeout", DefaultHealthTimeout, "Timeout for each request to the node")
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	dir := fs.String("data-dir", "", "Data directory of the node, for its tracked chains and the health parsd records there (default: ~/.pars)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	dataPath := *dir
+	if dataPath == "" {
+		dataPath, _ = defaultDataDir(os.UserHomeDir)
+	}
+	endpoint := "http://" + net.JoinHostPort(*host, strconv.Itoa(*port))
+	chains := trackedChains(filepath.Join(dataPath, "plugins"))
+	return probeHealth(os.Stdout, endpoint, *timeout, *asJSON, chains, healthStatePath(dataPath))
+}
+
+// probeHealth reports the health of each of chains at endpoint to w,
+// along with the components parsd recorded at healthState. An empty
+// healthState means parsd recorded none, so ParsVM, which runs in parsd,
+// is reported unhealthy. It returns the exit code: 0 if everything is
+// healthy, 1 otherwise.
+func probeHealth(w io.Writer, endpoint string, timeout time.Duration, asJSON bool, chains []string, healthState string) int {
+	agg := vm.NewHealthAggregator()
+	for _, chain := range chains {
+		c := vm.NewChainHealth(endpoint, chain)
+		c.SetTimeout(timeout)
+		agg.Register(chainComponents[chain], c)
+	}
+	if healthState == "" {
+		agg.Register("parsvm", recordedStatus{Message: "no health recorded by parsd in its data directory"})
+	} else {
+		for name, status := range recordedHealth(healthState, healthStateMaxAge, time.Now()) {
+			agg.Register(name, recordedStatus(status))
+		}
+	}
+	report := agg.Check()
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		names := make([]string, 0, len(report.Components))
+		for name := range report.Components {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			if status := report.Components[name]; status.Healthy {
+				fmt.Fprintf(w, "ok    %s\n", name)
+			} else {
+				fmt.Fprintf(w, "FAIL  %s: %s\n", name, status.Message)
+			}
+		}
+	}
+
+	if !report.Healthy {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/vm"
+)
+
+// stubLuxdHealth serves body from /ext/health with status
+func stubLuxdHealth(t *testing.T, status int, body string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ext/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// recordParsHealth records a healthy ParsVM as a running parsd would,
+// returning the state file
+func recordParsHealth(t *testing.T) string {
+	t.Helper()
+	agg := vm.NewHealthAggregator()
+	agg.Register("parsvm", recordedStatus{Healthy: true})
+	r := newHealthRecorder(agg, filepath.Join(t.TempDir(), healthStateFile), log.NewNoOpLogger())
+	if err := r.record(); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	return r.path
+}
+
+func TestProbeHealth(t *testing.T) {
+	state := recordParsHealth(t)
+	bothChains := []string{CChainAlias, SChainAlias}
+	healthy := stubLuxdHealth(t, http.StatusOK, `{"checks": {"C": {}, "S": {}}, "healthy": true}`)
+	var out bytes.Buffer
+	if code := probeHealth(&out, healthy, time.Second, false, bothChains, state); code != 0 {
+		t.Errorf("expected exit 0, got %d:\n%s", code, out.String())
+	}
+	if want := "ok    evm\nok    parsvm\nok    sessionvm\n"; out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+
+	unhealthy := stubLuxdHealth(t, http.StatusServiceUnavailable,
+		`{"checks": {"C": {}, "S": {"error": {"message": "vm plugin exited"}}}, "healthy": false}`)
+	out.Reset()
+	if code := probeHealth(&out, unhealthy, time.Second, false, bothChains, state); code != 1 {
+		t.Errorf("expected exit 1, got %d", code)
+	}
+	if !strings.Contains(out.String(), "FAIL  sessionvm: vm plugin exited") {
+		t.Errorf("expected the failing VM reported, got:\n%s", out.String())
+	}
+
+	// Without the SessionVM plugin the node does not track the S-Chain
+	out.Reset()
+	if code := probeHealth(&out, unhealthy, time.Second, false, []string{CChainAlias}, state); code != 0 {
+		t.Errorf("expected exit 0 without the S-Chain tracked, got %d:\n%s", code, out.String())
+	}
+	if strings.Contains(out.String(), "sessionvm") {
+		t.Errorf("expected no sessionvm entry, got:\n%s", out.String())
+	}
+
+	// ParsVM runs in parsd, so no recorded health means it is not known to run
+	out.Reset()
+	if code := probeHealth(&out, healthy, time.Second, false, bothChains, ""); code != 1 {
+		t.Errorf("expected exit 1 with no recorded health, got %d", code)
+	}
+	if !strings.Contains(out.String(), "FAIL  parsvm") {
+		t.Errorf("expected parsvm reported failing, got:\n%s", out.String())
+	}
+}
+
+func TestProbeHealthJSON(t *testing.T) {
+	unhealthy := stubLuxdHealth(t, http.StatusServiceUnavailable,
+		`{"checks": {"C": {"error": {"message": "not bootstrapped"}}, "S": {}}, "healthy": false}`)
+	var out bytes.Buffer
+	if code := probeHealth(&out, unhealthy, time.Second, true, []string{CChainAlias, SChainAlias}, recordParsHealth(t)); code != 1 {
+		t.Errorf("expected exit 1, got %d", code)
+	}
+
+	var report vm.HealthReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if report.Healthy {
+		t.Error("expected unhealthy report")
+	}
+	if evm := report.Components["evm"]; evm.Healthy || evm.Message != "not bootstrapped" {
+		t.Errorf("expected evm unhealthy, got %+v", evm)
+	}
+	for _, name := range []string{"sessionvm", "parsvm"} {
+		if !report.Components[name].Healthy {
+			t.Errorf("expected %s healthy, got %+v", name, report.Components[name])
+		}
+	}
+}
+
+func TestProbeHealthUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	var out bytes.Buffer
+	if code := probeHealth(&out, srv.URL, time.Second, false, []string{CChainAlias}, recordParsHealth(t)); code != 1 {
+		t.Errorf("expected exit 1 with the node down, got %d", code)
+	}
+}
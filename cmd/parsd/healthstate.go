@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/vm"
+)
+
+// healthStateFile is where a running parsd records the health of its own
+// components (watchdog, warp, ...) in its data directory, for `parsd health`
+const healthStateFile = "health.json"
+
+// DefaultHealthRecord is how often parsd records its components' health
+const DefaultHealthRecord = DefaultWatchdogPoll
+
+// healthStateMaxAge is how old a recorded health state may be before
+// `parsd health` treats parsd as no longer updating it
+const healthStateMaxAge = 4 * DefaultHealthRecord
+
+// healthState is a report as recorded in healthStateFile
+type healthState struct {
+	vm.HealthReport
+	Updated time.Time `json:"updated"`
+}
+
+// healthRecorder records the report of agg at path, replacing it
+// atomically each time
+type healthRecorder struct {
+	agg    *vm.HealthAggregator
+	path   string
+	logger log.Logger
+
+	// now is the recorder clock, replaceable in tests
+	now func() time.Time
+}
+
+func newHealthRecorder(agg *vm.HealthAggregator, path string, logger log.Logger) *healthRecorder {
+	return &healthRecorder{agg: agg, path: path, logger: logger, now: time.Now}
+}
+
+// run records now and then every interval until ctx is done
+func (r *healthRecorder) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.record(); err != nil {
+			r.logger.Warn("failed to record health state", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record writes the current report to path
+func (r *healthRecorder) record() error {
+	data, err := json.Marshal(healthState{HealthReport: r.agg.Check(), Updated: r.now()})
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// recordedStatus is a component status read back from a health state
+type recordedStatus vm.HealthStatus
+
+// Health implements vm.HealthChecker
+func (s recordedStatus) Health() vm.HealthStatus {
+	return vm.HealthStatus(s)
+}
+
+// recordedHealth returns the component statuses a running parsd recorded
+// at path, by component name. A state older than maxAge means parsd
+// stopped updating it, so every component is reported unhealthy; an
+// unreadable state is reported as an unhealthy "parsd" component.
+func recordedHealth(path string, maxAge time.Duration, now time.Time) map[string]vm.HealthStatus {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return map[string]vm.HealthStatus{"parsd": {Message: fmt.Sprintf("no health state: %v", err)}}
+	}
+	var state healthState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return map[string]vm.HealthStatus{"parsd": {Message: fmt.Sprintf("invalid health state: %v", err)}}
+	}
+	if age := now.Sub(state.Updated); age > maxAge {
+		stale := vm.HealthStatus{Message: fmt.Sprintf("health state not updated for %s", age.Round(time.Second))}
+		if len(state.Components) == 0 {
+			return map[string]vm.HealthStatus{"parsd": stale}
+		}
+		for name := range state.Components {
+			state.Components[name] = stale
+		}
+	}
+	return state.Components
+}
+
+// healthStatePath returns the health state file in dataDir, or "" if no
+// parsd has recorded one there
+func healthStatePath(dataDir string) string {
+	path := filepath.Join(dataDir, healthStateFile)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/vm"
+)
+
+func TestRecordedHealth(t *testing.T) {
+	dir := t.TempDir()
+	if got := healthStatePath(dir); got != "" {
+		t.Errorf("expected no state path before parsd records one, got %q", got)
+	}
+
+	source := &fakeHealth{status: vm.HealthStatus{Healthy: true}}
+	w := newWatchdog(source, time.Minute, log.NewNoOpLogger(), nil)
+	now := time.Unix(1700000000, 0)
+	w.now = func() time.Time { return now }
+	agg := vm.NewHealthAggregator()
+	agg.Register("watchdog", w)
+	r := newHealthRecorder(agg, filepath.Join(dir, healthStateFile), log.NewNoOpLogger())
+	r.now = w.now
+
+	w.check()
+	source.status = vm.HealthStatus{Message: "chain not responding"}
+	now = now.Add(2 * time.Minute)
+	w.check()
+	if err := r.record(); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	path := healthStatePath(dir)
+	if path == "" {
+		t.Fatal("expected the recorder to write its state")
+	}
+	if got, want := recordedHealth(path, healthStateMaxAge, now)["watchdog"], w.Health(); got != want {
+		t.Errorf("expected recorded %+v, got %+v", want, got)
+	}
+
+	source.status = vm.HealthStatus{Healthy: true}
+	w.check()
+	if err := r.record(); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if !recordedHealth(path, healthStateMaxAge, now)["watchdog"].Healthy {
+		t.Error("expected the recovery recorded")
+	}
+
+	// parsd no longer updating the state
+	if recordedHealth(path, healthStateMaxAge, now.Add(healthStateMaxAge+time.Second))["watchdog"].Healthy {
+		t.Error("expected a stale state unhealthy")
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if recordedHealth(path, healthStateMaxAge, now)["parsd"].Healthy {
+		t.Error("expected an unreadable state unhealthy")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/parsdao/node/messaging"
+)
+
+// runJournal implements `parsd journal replay --id <msgID>`
+func runJournal(args []string) int {
+	if len(args) == 0 || args[0] != "replay" {
+		fmt.Fprintln(os.Stderr, "usage: parsd journal replay --id <message-id> [--data-dir path | --file path]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("journal replay", flag.ContinueOnError)
+	file := fs.String("file", "", "Journal file (default: <data-dir>/journal/messages.log)")
+	dir := fs.String("data-dir", "", "Data directory of the node (default: ~/.pars)")
+	id := fs.String("id", "", "Message ID to reconstruct")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *id == "" {
+		fmt.Fprintln(os.Stderr, "--id is required")
+		return 2
+	}
+
+	path := *file
+	if path == "" {
+		dataPath := *dir
+		if dataPath == "" {
+			dataPath, _ = defaultDataDir(os.UserHomeDir)
+		}
+		path = filepath.Join(dataPath, "journal", "messages.log")
+	}
+
+	if err := replayJournal(os.Stdout, path, *id); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// replayJournal prints the lifecycle of a message from the journal
+func replayJournal(w io.Writer, path, id string) error {
+	entries, err := messaging.ReplayJournal(path, id)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no journal entries for message %s", id)
+	}
+
+	fmt.Fprintf(w, "message %s\n", id)
+	for _, e := range entries {
+		fmt.Fprintf(w, "  %s  %-10s  from=%s to=%s size=%d\n",
+			e.Time.Format(time.RFC3339Nano), e.Event, e.SenderID, e.RecipientID, e.Size)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/messaging"
+)
+
+func TestReplayJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.log")
+
+	j, err := messaging.OpenJournal(config.JournalConfig{Path: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := &messaging.Message{ID: "msg-1", Ciphertext: []byte("secret ciphertext")}
+	for _, event := range []messaging.JournalEvent{messaging.EventEnqueued, messaging.EventDelivered} {
+		if err := j.Record(event, msg); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	j.Close()
+
+	var out bytes.Buffer
+	if err := replayJournal(&out, path, "msg-1"); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "enqueued") || !strings.Contains(got, "delivered") {
+		t.Errorf("expected timeline events in output, got:\n%s", got)
+	}
+	if strings.Contains(got, "secret ciphertext") {
+		t.Error("replay output must not include message content")
+	}
+
+	if err := replayJournal(&out, path, "msg-unknown"); err == nil {
+		t.Error("expected error for unknown message")
+	}
+}
+
+func TestRunJournalDataDir(t *testing.T) {
+	dir := t.TempDir()
+	j, err := messaging.OpenJournal(config.JournalConfig{Path: filepath.Join(dir, "journal", "messages.log")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := j.Record(messaging.EventEnqueued, &messaging.Message{ID: "msg-1"}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	j.Close()
+
+	if code := runJournal([]string{"replay", "--id", "msg-1", "--data-dir", dir}); code != 0 {
+		t.Errorf("expected the journal found under --data-dir, got exit %d", code)
+	}
+	if code := runJournal([]string{"replay", "--id", "msg-1", "--data-dir", t.TempDir()}); code != 1 {
+		t.Errorf("expected exit 1 for an empty data dir, got %d", code)
+	}
+}
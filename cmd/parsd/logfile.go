@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// DefaultLogMaxSizeMB is the size at which --log-file is rotated
+	DefaultLogMaxSizeMB = 100
+
+	// DefaultLogMaxBackups is the number of rotated log files kept
+	DefaultLogMaxBackups = 5
+)
+
+// openLogFile opens a size-rotated log file at path, creating its directory
+func openLogFile(path string, maxSizeMB, maxBackups int) (*lumberjack.Logger, error) {
+	if maxSizeMB <= 0 {
+		return nil, fmt.Errorf("log max size must be positive, got %d", maxSizeMB)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/log"
+)
+
+func TestLogFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "parsd.log")
+
+	lf, err := openLogFile(path, 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer lf.Close()
+
+	logger := log.New("component", "parsd").Output(lf)
+	logger.Info("hello from parsd")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("log file not created: %v", err)
+	}
+	if !strings.Contains(string(data), "hello from parsd") {
+		t.Errorf("expected log line in file, got %q", data)
+	}
+
+	// Two writes of 600KB exceed the 1MB limit and force a rotation
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 2; i++ {
+		if _, err := lf.Write(chunk); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	backups, err := filepath.Glob(filepath.Join(filepath.Dir(path), "parsd-*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Errorf("expected one rotated backup, got %v", backups)
+	}
+}
+
+func TestLogFileInvalidSize(t *testing.T) {
+	if _, err := openLogFile(filepath.Join(t.TempDir(), "parsd.log"), 0, 1); err == nil {
+		t.Error("expected error for zero max size")
+	}
+}
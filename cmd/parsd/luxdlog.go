@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/luxfi/log"
+)
+
+// luxdLogLine matches luxd's default log format:
+//
+//	[01-02|15:04:05.000] INFO <C Chain> snowman/engine.go:42 message {"k":"v"}
+var luxdLogLine = regexp.MustCompile(`^\[[^\]]*\]\s+([A-Z]+)\s+(?:<([^>]+)>\s+)?(?:\S+\.go:\d+\s+)?(.*)$`)
+
+// luxdLevels maps luxd level names to parsd log levels. FATAL is logged as
+// an error so re-emitting it never exits parsd.
+var luxdLevels = map[string]log.Level{
+	"VERBO": log.TraceLevel,
+	"TRACE": log.TraceLevel,
+	"DEBUG": log.DebugLevel,
+	"INFO":  log.InfoLevel,
+	"WARN":  log.WarnLevel,
+	"ERROR": log.ErrorLevel,
+	"FATAL": log.ErrorLevel,
+}
+
+// luxdLogWriter re-emits luxd output through the parsd logger with
+// source=luxd, one record per line, keeping luxd's level when it can be
+// parsed
+type luxdLogWriter struct {
+	logger log.Logger
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func newLuxdLogWriter(logger log.Logger) *luxdLogWriter {
+	return &luxdLogWriter{logger: logger}
+}
+
+// Write implements io.Writer, buffering any trailing partial line
+func (w *luxdLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits any buffered partial line
+func (w *luxdLogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.emit(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *luxdLogWriter) emit(line string) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	m := luxdLogLine.FindStringSubmatch(line)
+	if m == nil {
+		w.logger.Log(log.InfoLevel, line, "source", "luxd")
+		return
+	}
+
+	level, ok := luxdLevels[m[1]]
+	if !ok {
+		level = log.InfoLevel
+	}
+	ctx := []interface{}{"source", "luxd"}
+	if m[2] != "" {
+		ctx = append(ctx, "chain", m[2])
+	}
+	w.logger.Log(level, m[3], ctx...)
+}
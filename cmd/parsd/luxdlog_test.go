@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/log"
+)
+
+func TestLuxdLogWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newLuxdLogWriter(log.NewWriter(&out))
+
+	// A line split across writes is emitted once, when complete
+	w.Write([]byte("[10-15|12:00:00.000] WARN <C Chain> snowman/engine.go:42 "))
+	if out.Len() != 0 {
+		t.Fatalf("expected partial line buffered, got %q", out.String())
+	}
+	w.Write([]byte("block verification slow\nplain output"))
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d: %q", len(lines), out.String())
+	}
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("invalid record %q: %v", lines[0], err)
+	}
+	want := map[string]interface{}{
+		"source":  "luxd",
+		"chain":   "C Chain",
+		"level":   "warn",
+		"message": "block verification slow",
+	}
+	for k, v := range want {
+		if rec[k] != v {
+			t.Errorf("%s: expected %v, got %v (record %s)", k, v, rec[k], lines[0])
+		}
+	}
+
+	if !strings.Contains(lines[1], `"source":"luxd"`) || !strings.Contains(lines[1], "plain output") {
+		t.Errorf("expected unparsed line passed through with source, got %s", lines[1])
+	}
+}
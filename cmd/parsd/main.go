@@ -15,20 +15,41 @@
 //	parsd --testnet           # Run testnet
 //	parsd --devnet            # Run local 5-node devnet
 //	parsd --network-id=7071   # Custom network
+//	parsd --config=pars.json  # Load node config (consensus, features, ...)
+//	parsd journal replay --id=<msg>  # Reconstruct a message's lifecycle
+//	parsd bench crypto               # Measure crypto throughput on this host
+//	parsd version                    # Print build metadata and the luxd in use
+//	parsd health [--json]            # Probe a running node's chain health
 
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"maps"
+	"net"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"syscall"
+	"time"
 
+	"github.com/luxfi/ids"
 	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/genesis"
+	"github.com/parsdao/node/storage"
+	"github.com/parsdao/node/vm"
 )
 
 const (
@@ -41,26 +62,121 @@ const (
 	EVMID       = "srEXiWaHuhNyGwPUi444Tu47ZEDwxTWrbQiuD7FmgSAQ6X7Dy" // Lux EVM
 	SessionVMID = "speKUgLBX6WRD5cfGeEfLa43LxTXUBckvtv4td6F3eTXvRP48" // Session VM
 
+	// Chain aliases passed to --track-chains
+	CChainAlias = "C"
+	SChainAlias = "S"
+
 	// Default ports
 	DefaultHTTPPort    = 9660
 	DefaultStakingPort = 9659
+
+	// DefaultShutdownTimeout is how long luxd has to exit after SIGTERM
+	DefaultShutdownTimeout = 30 * time.Second
+
+	// FallbackDataDir is the data directory when there is no home directory
+	FallbackDataDir = "/var/lib/pars"
+
+	// DefaultMinFreeDisk is the free space required in the data directory
+	DefaultMinFreeDisk = 1024 * 1024 * 1024 // 1GB
 )
 
 var (
-	testnet   = flag.Bool("testnet", false, "Run Pars testnet (network-id=7071)")
-	devnet    = flag.Bool("devnet", false, "Run Pars devnet (network-id=7072)")
-	networkID = flag.Int("network-id", 0, "Network ID (default: 7070 mainnet)")
-	httpPort  = flag.Int("http-port", DefaultHTTPPort, "HTTP API port")
-	stakingPort = flag.Int("staking-port", DefaultStakingPort, "Staking/P2P port")
-	dataDir   = flag.String("data-dir", "", "Data directory (default: ~/.pars)")
-	genesis   = flag.String("genesis", "", "Path to genesis file")
-	bootstrap = flag.Bool("bootstrap", false, "Bootstrap new network (genesis validators only)")
+	testnet            = flag.Bool("testnet", false, "Run Pars testnet (network-id=7071)")
+	devnet             = flag.Bool("devnet", false, "Run Pars devnet (network-id=7072)")
+	networkID          = flag.Int("network-id", 0, "Network ID (default: 7070 mainnet)")
+	httpPort           = flag.Int("http-port", DefaultHTTPPort, "HTTP API port")
+	stakingPort        = flag.Int("staking-port", DefaultStakingPort, "Staking/P2P port")
+	httpHost           = flag.String("http-host", "", "Interface for the HTTP API (default: luxd's own)")
+	stakingHost        = flag.String("staking-host", "", "Interface for staking/P2P (default: luxd's own)")
+	dataDir            = flag.String("data-dir", "", "Data directory (default: ~/.pars)")
+	genesisFile        = flag.String("genesis", "", "Path to genesis file")
+	bootstrap          = flag.Bool("bootstrap", false, "Bootstrap new network (genesis validators only)")
+	join               = flag.Bool("join", false, "Join an existing network, fast-syncing state from --bootnodes instead of replaying from genesis")
+	bootnodes          = flag.String("bootnodes", "", "Comma-separated NodeID-...@host:port peers to sync from with --join")
+	shutdownTimeout    = flag.Duration("shutdown-timeout", DefaultShutdownTimeout, "Time luxd has to exit after SIGTERM before it is killed")
+	minFreeDisk        = flag.Uint64("min-free-disk", DefaultMinFreeDisk, "Minimum free disk space in bytes required in the data directory (0 disables)")
+	configFile         = flag.String("config", "", "Path to a JSON node config file")
+	verifyConfig       = flag.String("verify-config", "", "ML-DSA-65 public key file; refuse to start unless --config has a valid <config>.sig")
+	cryptoOverrides    = addCryptoFlags(flag.CommandLine)
+	logFile            = flag.String("log-file", "", "Also write parsd logs to this size-rotated file")
+	logMaxSize         = flag.Int("log-max-size", DefaultLogMaxSizeMB, "Size in MB at which --log-file is rotated")
+	logMaxBackups      = flag.Int("log-max-backups", DefaultLogMaxBackups, "Number of rotated --log-file backups to keep")
+	logLuxdOutput      = flag.Bool("log-luxd-output", false, "Also copy luxd stdout/stderr into --log-file")
+	rawLuxdLogs        = flag.Bool("raw-luxd-logs", false, "Pass luxd stderr through unmodified instead of re-logging it as source=luxd")
+	refreshPlugins     = flag.Bool("refresh-plugins", false, "Re-resolve plugin links on start and repoint any whose binary has moved, including links set up by hand")
+	stallTimeout       = flag.Duration("stall-timeout", 0, "Alert when luxd reports the C-Chain unhealthy for this long (0 disables)")
+	stallRestart       = flag.Bool("stall-restart", false, "Stop luxd when --stall-timeout trips, exiting non-zero so the service manager restarts parsd")
+	luxdLogLevel       = flag.String("luxd-log-level", "", "luxd log level, independent of parsd logging (default: luxd's own)")
+	showVersion        = flag.Bool("version", false, "Print version information and exit")
+	downloadPlugins    = flag.Bool("download-plugins", false, "Download VM plugins not found locally from --plugin-release-url, verified against --plugin-checksums")
+	pluginRelease      = flag.String("plugin-release-url", "", "Base URL of plugin release binaries, named <vm>-<os>-<arch>")
+	pluginChecksumFile = flag.String("plugin-checksums", "", "sha256sum-format file pinning the plugin binaries --download-plugins accepts, by <vm>-<os>-<arch>")
 )
 
+// luxdLogLevels are the levels accepted by luxd --log-level
+var luxdLogLevels = []string{"off", "fatal", "error", "warn", "info", "trace", "debug", "verbo"}
+
+var (
+	ErrConflictingBootMode = errors.New("--bootstrap and --join are mutually exclusive")
+	ErrInvalidBootnode     = errors.New("invalid bootnode")
+)
+
+// subcommands run in place of launching luxd
+var subcommands = map[string]func(args []string) int{
+	"bench":        runBench,
+	"check-config": runCheckConfig,
+	"health":       runHealth,
+	"journal":      runJournal,
+	"plugins":      runPlugins,
+	"version":      runVersion,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+	os.Exit(runNode())
+}
+
+// runNode launches luxd as a Pars node and returns parsd's exit code. It
+// returns rather than exiting so its deferred cleanup runs.
+func runNode() int {
 	flag.Parse()
+	if *showVersion {
+		return runVersion(nil)
+	}
 	logger := log.New("component", "parsd")
 
+	// Tee logs to a rotating file for hosts without a log collector
+	luxdStdout, luxdStderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	if *logFile != "" {
+		lf, err := openLogFile(*logFile, *logMaxSize, *logMaxBackups)
+		if err != nil {
+			logger.Error("failed to open log file", "error", err)
+			return 1
+		}
+		defer lf.Close()
+
+		logger = logger.Output(io.MultiWriter(os.Stderr, lf))
+		if *logLuxdOutput {
+			luxdStdout = io.MultiWriter(os.Stdout, lf)
+			luxdStderr = io.MultiWriter(os.Stderr, lf)
+		}
+	}
+
+	// Re-emit luxd stderr as structured parsd logs unless asked not to
+	var luxdLogs *luxdLogWriter
+	if !*rawLuxdLogs {
+		luxdLogs = newLuxdLogWriter(logger)
+		luxdStderr = luxdLogs
+	}
+
+	// Stop setup early, or shut luxd down, when parsd is asked to stop
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// Determine network
 	netID := ParsMainnetID
 	netName := "mainnet"
@@ -76,52 +192,133 @@ func main() {
 	}
 
 	// Determine data directory
-	homeDir, err := os.UserHomeDir()
+	dataPath := *dataDir
+	if dataPath == "" {
+		var homeErr error
+		dataPath, homeErr = defaultDataDir(os.UserHomeDir)
+		if homeErr != nil {
+			logger.Warn("home directory unavailable, using fallback data directory",
+				"datadir", dataPath,
+				"error", homeErr,
+			)
+		}
+	}
+
+	// The config derives message storage and TTL bounds from the network
+	// and data directory luxd runs with
+	opts := cryptoOverrides.options()
+	opts.NetworkID = uint32(netID)
+	opts.DataDir = dataPath
+	cfg, err := loadConfig(*configFile, *verifyConfig, opts)
 	if err != nil {
-		logger.Error("failed to get home directory", "error", err)
-		os.Exit(1)
+		logger.Error("failed to load config", "error", err)
+		return 1
+	}
+	if unknown := cfg.Features.Unknown(); len(unknown) > 0 {
+		logger.Warn("ignoring unknown feature flags", "features", unknown)
+	}
+	if unapplied := unappliedConsensus(cfg.Consensus); len(unapplied) > 0 {
+		logger.Warn("ignoring consensus settings luxd has no option for", "settings", unapplied)
 	}
 
-	dataPath := *dataDir
-	if dataPath == "" {
-		dataPath = filepath.Join(homeDir, ".pars")
+	// Fail early with a clear error rather than a cryptic mkdir/symlink failure
+	if err := storage.CheckDiskSpace(dataPath, *minFreeDisk); err != nil {
+		logger.Error("not enough free disk space in data directory",
+			"datadir", dataPath,
+			"error", err,
+		)
+		logger.Info("Free up space, choose another --data-dir, or lower --min-free-disk")
+		return 1
+	}
+
+	listeners := []listenAddr{
+		{name: "http", host: *httpHost, port: *httpPort},
+		{name: "staking", host: *stakingHost, port: *stakingPort},
+	}
+	for _, l := range listeners {
+		if l.host == "" {
+			continue
+		}
+		if err := validateHost(l.host); err != nil {
+			logger.Error("invalid --"+l.name+"-host", "error", err)
+			return 1
+		}
+	}
+
+	// luxd reports a busy port only as a bind error deep in its own logs
+	if err := checkPortsFree(listeners); err != nil {
+		logger.Error("required port is not free", "error", err)
+		logger.Info("Stop the process using the port, or choose another with --http-port/--staking-port")
+		return 1
 	}
 
 	// Ensure directories exist
 	pluginDir := filepath.Join(dataPath, "plugins")
 	if err := os.MkdirAll(pluginDir, 0755); err != nil {
 		logger.Error("failed to create plugin directory", "error", err)
-		os.Exit(1)
+		return 1
 	}
 
 	// Setup plugins
-	if err := setupPlugins(pluginDir, logger); err != nil {
-		logger.Error("failed to setup plugins", "error", err)
-		os.Exit(1)
+	var fetch pluginFetch
+	if *downloadPlugins {
+		if *pluginRelease == "" {
+			logger.Error("--download-plugins requires --plugin-release-url")
+			return 1
+		}
+		checksums, err := loadPluginChecksums(*pluginChecksumFile)
+		if err != nil {
+			logger.Error("failed to load plugin checksums", "error", err)
+			return 1
+		}
+		fetch = newPluginDownloader(*pluginRelease, checksums).fetch
+	}
+	if err := setupPlugins(ctx, pluginDir, *refreshPlugins, fetch, logger); err != nil {
+		if ctx.Err() != nil {
+			logger.Info("interrupted during plugin setup, exiting before starting luxd")
+		} else {
+			logger.Error("failed to setup plugins", "error", err)
+		}
+		return 1
 	}
 
 	// Build luxd command
 	args := buildLuxdArgs(netID, dataPath, pluginDir)
 
 	// Add network-specific flags
-	args = append(args,
-		fmt.Sprintf("--http-port=%d", *httpPort),
-		fmt.Sprintf("--staking-port=%d", *stakingPort),
-	)
+	for _, l := range listeners {
+		args = append(args, l.luxdArgs()...)
+	}
+
+	// --bootstrap starts a network, --join syncs from one
+	bootArgs, err := bootModeArgs(*bootstrap, *join, *bootnodes)
+	if err != nil {
+		logger.Error("invalid boot mode", "error", err)
+		return 1
+	}
+	args = append(args, bootArgs...)
 
 	// Add genesis if specified or for bootstrap
-	if *genesis != "" {
-		args = append(args, fmt.Sprintf("--genesis-file=%s", *genesis))
+	if *genesisFile != "" {
+		args = append(args, fmt.Sprintf("--genesis-file=%s", *genesisFile))
 	} else if *bootstrap {
 		// Use embedded genesis for bootstrap
 		genesisPath := filepath.Join(dataPath, "genesis.json")
-		if err := writeEmbeddedGenesis(genesisPath, netName); err != nil {
+		if err := writeEmbeddedGenesis(genesisPath, netName, cfg.EVM.ChainID); err != nil {
 			logger.Error("failed to write genesis", "error", err)
-			os.Exit(1)
+			return 1
 		}
 		args = append(args, fmt.Sprintf("--genesis-file=%s", genesisPath))
 	}
 
+	// luxd verbosity is set separately from parsd's own logging
+	logArgs, err := luxdLogLevelArgs(*luxdLogLevel)
+	if err != nil {
+		logger.Error("invalid --luxd-log-level", "error", err)
+		return 1
+	}
+	args = append(args, logArgs...)
+
 	// Pass through remaining flags
 	args = append(args, flag.Args()...)
 
@@ -130,7 +327,9 @@ func main() {
 		"network-id", netID,
 		"datadir", dataPath,
 		"plugins", pluginDir,
+		"http-host", *httpHost,
 		"http-port", *httpPort,
+		"staking-host", *stakingHost,
 		"staking-port", *stakingPort,
 	)
 
@@ -139,39 +338,115 @@ func main() {
 	if err != nil {
 		logger.Error("luxd not found", "error", err)
 		logger.Info("Install luxd: go install github.com/luxfi/node/cmd/luxd@latest")
-		os.Exit(1)
+		return 1
 	}
 
-	// Execute luxd
-	cmd := exec.Command(luxdPath, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	// Watch for an unhealthy C-Chain; stopping luxd is the only restart
+	// parsd can do itself
+	luxdCtx, stopLuxd := context.WithCancel(ctx)
+	defer stopLuxd()
+	// Why parsd stopped luxd itself, if it did
+	var stopReason atomic.Pointer[string]
+	stopFor := func(reason string) {
+		stopReason.Store(&reason)
+		stopLuxd()
+	}
+	endpoint := "http://" + net.JoinHostPort(*httpHost, strconv.Itoa(*httpPort))
 
-	// Handle signals
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	// parsd's own components, recorded for `parsd health`
+	nodeHealth := vm.NewHealthAggregator()
 
-	if err := cmd.Start(); err != nil {
-		logger.Error("failed to start luxd", "error", err)
-		os.Exit(1)
+	// Messaging runs in parsd itself, beside luxd
+	parsVM, err := vm.NewParsVM(cfg.Pars, cfg.Crypto)
+	if err != nil {
+		logger.Error("failed to create ParsVM", "error", err)
+		return 1
 	}
-
-	go func() {
-		<-sigCh
-		logger.Info("shutting down parsd...")
-		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
-			logger.Error("failed to signal luxd", "error", err)
+	if err := parsVM.Start(luxdCtx); err != nil {
+		logger.Error("failed to start ParsVM", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := parsVM.Stop(); err != nil {
+			logger.Warn("ParsVM did not stop cleanly", "error", err)
 		}
 	}()
+	nodeHealth.Register("parsvm", parsVM)
+	if *stallTimeout > 0 {
+		var onStall func()
+		if *stallRestart {
+			onStall = func() { stopFor("a C-Chain stall") }
+		}
+		wd := newWatchdog(vm.NewChainHealth(endpoint, CChainAlias), *stallTimeout, logger, onStall)
+		nodeHealth.Register("watchdog", wd)
+		go wd.run(luxdCtx, min(DefaultWatchdogPoll, *stallTimeout))
+	}
+	// No warp client is started: it cannot submit messages yet (see
+	// warp.localSubmitter), so probing warp.luxEndpoint, by default the
+	// public API, would only tie node health to a remote endpoint
+	if cfg.EVM.Enabled && cfg.EVM.PrecompileCheck != "off" {
+		evm, err := vm.NewEVM(withCChainRPC(cfg.EVM, endpoint), cfg.Features)
+		if err == nil {
+			err = evm.Start(luxdCtx)
+		}
+		if err != nil {
+			logger.Error("failed to start the EVM", "error", err)
+			return 1
+		}
+		var onFail func()
+		if cfg.EVM.PrecompileCheck == "fail" {
+			onFail = func() { stopFor("missing precompiles") }
+		}
+		check := newPrecompileCheck(evm, vm.NewChainHealth(endpoint, CChainAlias), logger, onFail)
+		nodeHealth.Register("precompiles", check)
+		go check.run(luxdCtx, DefaultWatchdogPoll)
+	}
+	go newHealthRecorder(nodeHealth, filepath.Join(dataPath, healthStateFile), logger).run(luxdCtx, DefaultHealthRecord)
 
-	if err := cmd.Wait(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			os.Exit(exitErr.ExitCode())
+	err = runLuxd(luxdCtx, logger, luxdPath, args, luxdStdout, luxdStderr, *shutdownTimeout)
+	if luxdLogs != nil {
+		luxdLogs.Flush()
+	}
+	if reason := stopReason.Load(); reason != nil && ctx.Err() == nil {
+		logger.Error("stopped luxd, exiting non-zero", "reason", *reason)
+		return 1
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
 		}
 		logger.Error("luxd exited with error", "error", err)
-		os.Exit(1)
+		return 1
+	}
+	return 0
+}
+
+// runLuxd runs luxd until it exits or ctx is cancelled. On cancellation
+// luxd receives SIGTERM and is killed if it has not exited within timeout.
+func runLuxd(ctx context.Context, logger log.Logger, luxdPath string, args []string, stdout, stderr io.Writer, timeout time.Duration) error {
+	cmd := exec.CommandContext(ctx, luxdPath, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Stdin = os.Stdin
+
+	// SIGTERM first; WaitDelay escalates to SIGKILL once the timeout passes
+	cmd.Cancel = func() error {
+		logger.Info("shutting down parsd...", "timeout", timeout)
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = timeout
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start luxd: %w", err)
+	}
+
+	err := cmd.Wait()
+	if ctx.Err() != nil && cmd.ProcessState != nil && cmd.ProcessState.Success() {
+		// Clean exit after a requested shutdown
+		return nil
 	}
+	return err
 }
 
 // buildLuxdArgs returns the luxd arguments for Pars network
@@ -192,11 +467,97 @@ func buildLuxdArgs(networkID int, dataDir, pluginDir string) []string {
 		// Chain config for PQ precompiles
 		"--chain-config-content=" + getParsChainConfig(),
 
-		// Track all chains
-		"--track-chains=all",
+		// Track only chains whose VMs are linked
+		"--track-chains=" + strings.Join(trackedChains(pluginDir), ","),
 	}
 }
 
+// trackedChains returns the chains luxd should track given the plugins
+// present in pluginDir. The C-Chain is always tracked; the S-Chain only
+// when the SessionVM plugin is linked, since luxd cannot bootstrap it
+// otherwise.
+func trackedChains(pluginDir string) []string {
+	chains := []string{CChainAlias}
+	if _, err := os.Stat(filepath.Join(pluginDir, SessionVMID)); err == nil {
+		chains = append(chains, SChainAlias)
+	}
+	return chains
+}
+
+// luxdLogLevelArgs translates --luxd-log-level into luxd arguments
+func luxdLogLevelArgs(level string) ([]string, error) {
+	if level == "" {
+		return nil, nil
+	}
+	level = strings.ToLower(level)
+	if !slices.Contains(luxdLogLevels, level) {
+		return nil, fmt.Errorf("unknown level %q (valid: %s)", level, strings.Join(luxdLogLevels, ", "))
+	}
+	return []string{"--log-level=" + level}, nil
+}
+
+// bootModeArgs returns the luxd beacon and sync arguments for --bootstrap
+// or --join. A bootstrapping node has no beacons; a joining node uses
+// bootnodes as beacons and state-syncs from them.
+func bootModeArgs(bootstrap, join bool, bootnodes string) ([]string, error) {
+	switch {
+	case bootstrap && join:
+		return nil, ErrConflictingBootMode
+	case bootstrap:
+		return []string{"--bootstrap-ips=", "--bootstrap-ids="}, nil
+	case !join:
+		return nil, nil
+	}
+
+	var ips, nodeIDs []string
+	for _, node := range strings.Split(bootnodes, ",") {
+		node = strings.TrimSpace(node)
+		if node == "" {
+			continue
+		}
+		id, addr, ok := strings.Cut(node, "@")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not NodeID@host:port", ErrInvalidBootnode, node)
+		}
+		if _, err := ids.NodeIDFromString(id); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidBootnode, node, err)
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidBootnode, node, err)
+		}
+		nodeIDs = append(nodeIDs, id)
+		ips = append(ips, addr)
+	}
+	if len(nodeIDs) == 0 {
+		return nil, fmt.Errorf("%w: --join needs at least one of --bootnodes", ErrInvalidBootnode)
+	}
+
+	return []string{
+		"--bootstrap-ips=" + strings.Join(ips, ","),
+		"--bootstrap-ids=" + strings.Join(nodeIDs, ","),
+		"--state-sync-ips=" + strings.Join(ips, ","),
+		"--state-sync-ids=" + strings.Join(nodeIDs, ","),
+	}, nil
+}
+
+// unappliedConsensus returns the consensus settings changed from their
+// defaults. luxd takes no chain config or flag for block time, validator
+// stake or validator count, so parsd cannot apply them.
+func unappliedConsensus(consensus config.ConsensusConfig) []string {
+	defaults := config.Default().Consensus
+	var changed []string
+	if consensus.BlockTimeMs != defaults.BlockTimeMs {
+		changed = append(changed, "consensus.blockTimeMs")
+	}
+	if consensus.Validators.MinStake != defaults.Validators.MinStake {
+		changed = append(changed, "consensus.validators.minStake")
+	}
+	if consensus.Validators.MaxCount != defaults.Validators.MaxCount {
+		changed = append(changed, "consensus.validators.maxCount")
+	}
+	return changed
+}
+
 // getParsChainConfig returns the chain configuration with PQ precompiles
 func getParsChainConfig() string {
 	config := map[string]interface{}{
@@ -211,11 +572,11 @@ func getParsChainConfig() string {
 			},
 			// Lux Cross-Chain Precompiles (native access to Lux ecosystem)
 			"crossChainPrecompiles": map[string]string{
-				"xchain":  "0x1000", // X-Chain: PARS liquidity & staking
-				"tchain":  "0x1100", // T-Chain: Trading/DEX access
-				"zchain":  "0x1200", // Z-Chain: Zero-knowledge proofs
-				"warp":    "0x1300", // Warp: Cross-subnet messaging
-				"oracle":  "0x1400", // Oracle: Price feeds
+				"xchain": "0x1000", // X-Chain: PARS liquidity & staking
+				"tchain": "0x1100", // T-Chain: Trading/DEX access
+				"zchain": "0x1200", // Z-Chain: Zero-knowledge proofs
+				"warp":   "0x1300", // Warp: Cross-subnet messaging
+				"oracle": "0x1400", // Oracle: Price feeds
 			},
 			// DEX/HFT precompiles for native trading
 			"dexPrecompiles": map[string]string{
@@ -233,57 +594,211 @@ func getParsChainConfig() string {
 		},
 		// X-Chain staking configuration
 		"pars-staking": map[string]interface{}{
-			"minStake":       15000,           // 15,000 PARS minimum
-			"lockPeriod":     86400 * 30,      // 30 days lock
-			"rewardRate":     0.08,            // 8% APY year 1
-			"xchainBridge":   true,            // Enable X-Chain staking bridge
-			"feeRecipient":   "X-pars1...",    // X-Chain fee collection
+			"minStake":     15000,        // 15,000 PARS minimum
+			"lockPeriod":   86400 * 30,   // 30 days lock
+			"rewardRate":   0.08,         // 8% APY year 1
+			"xchainBridge": true,         // Enable X-Chain staking bridge
+			"feeRecipient": "X-pars1...", // X-Chain fee collection
 		},
 	}
 	data, _ := json.Marshal(config)
 	return string(data)
 }
 
-// writeEmbeddedGenesis writes the network genesis to a file
-func writeEmbeddedGenesis(path, network string) error {
-	// For now, return an error - in production this would embed the genesis
-	// or fetch from a known location
-	return fmt.Errorf("embedded genesis not available for %s - use --genesis flag", network)
+// genesisNetworks are the networks with an embedded genesis, by name
+var genesisNetworks = map[string]uint32{
+	"mainnet": ParsMainnetID,
+	"testnet": ParsTestnetID,
+	"devnet":  ParsDevnetID,
 }
 
-// setupPlugins ensures EVM and SessionVM binaries are in the plugin directory
-func setupPlugins(pluginDir string, logger log.Logger) error {
-	// Check for EVM plugin
-	evmDst := filepath.Join(pluginDir, EVMID)
-	if _, err := os.Stat(evmDst); os.IsNotExist(err) {
-		evmSrc, err := findEVM()
-		if err != nil {
-			logger.Warn("EVM plugin not found", "error", err)
-		} else {
-			if err := os.Symlink(evmSrc, evmDst); err != nil && !os.IsExist(err) {
-				return fmt.Errorf("failed to link EVM plugin: %w", err)
+// writeEmbeddedGenesis writes the embedded genesis for the named network,
+// with the C-Chain at chainID, to path
+func writeEmbeddedGenesis(path, network string, chainID uint64) error {
+	networkID, ok := genesisNetworks[network]
+	if !ok {
+		names := slices.Sorted(maps.Keys(genesisNetworks))
+		return fmt.Errorf("no embedded genesis for network %q (supported: %s); use --genesis", network, strings.Join(names, ", "))
+	}
+	data, err := genesis.Build(networkID, chainID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// pluginLink is a VM plugin that parsd links into the luxd plugin directory
+type pluginLink struct {
+	name    string
+	vmID    string
+	find    func() (string, error)
+	missing string // Logged when the plugin binary is not found
+}
+
+// vmPlugins are the plugins linked by setupPlugins
+var vmPlugins = []pluginLink{
+	{name: "EVM", vmID: EVMID, find: findEVM, missing: "EVM plugin not found"},
+	{name: "SessionVM", vmID: SessionVMID, find: findSessionVM, missing: "SessionVM plugin not found, S-Chain will not be tracked"},
+}
+
+// setupPlugins ensures EVM and SessionVM binaries are in the plugin
+// directory, installing any not found locally with fetch if it is set
+func setupPlugins(ctx context.Context, pluginDir string, refresh bool, fetch pluginFetch, logger log.Logger) error {
+	return linkPlugins(ctx, pluginDir, vmPlugins, refresh, fetch, logger)
+}
+
+// linkPlugins symlinks each missing plugin into pluginDir. With refresh,
+// existing links are re-resolved and repointed when the plugin is now
+// found elsewhere (e.g. after an upgrade); plugin files that are not
+// symlinks are never touched. A plugin not found locally is installed with
+// fetch, failing setup if that fails; with a nil fetch it is skipped with a
+// warning. If it fails or ctx is cancelled part way,
+// the links it created or repointed are restored so an interrupted setup
+// leaves the directory as it found it.
+func linkPlugins(ctx context.Context, pluginDir string, plugins []pluginLink, refresh bool, fetch pluginFetch, logger log.Logger) (err error) {
+	var created []string
+	replaced := make(map[string]string) // link -> previous target
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, link := range created {
+			if rmErr := os.Remove(link); rmErr != nil && !os.IsNotExist(rmErr) {
+				logger.Warn("failed to remove partial plugin link", "link", link, "error", rmErr)
 			}
-			logger.Info("linked EVM plugin", "src", evmSrc, "dst", evmDst)
 		}
-	}
+		for link, old := range replaced {
+			if rbErr := replaceSymlink(link, old); rbErr != nil {
+				logger.Warn("failed to restore plugin link", "link", link, "target", old, "error", rbErr)
+			}
+		}
+	}()
+
+	for _, p := range plugins {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("plugin setup interrupted: %w", err)
+		}
 
-	// Check for SessionVM plugin
-	sessionDst := filepath.Join(pluginDir, SessionVMID)
-	if _, err := os.Stat(sessionDst); os.IsNotExist(err) {
-		sessionSrc, err := findSessionVM()
+		dst := filepath.Join(pluginDir, p.vmID)
+		var old string
+		if fi, err := os.Lstat(dst); !os.IsNotExist(err) {
+			if !refresh || err != nil || fi.Mode()&os.ModeSymlink == 0 {
+				continue
+			}
+			if old, err = os.Readlink(dst); err != nil {
+				continue
+			}
+		}
+
+		src, err := p.find()
 		if err != nil {
-			logger.Warn("SessionVM plugin not found", "error", err)
-		} else {
-			if err := os.Symlink(sessionSrc, sessionDst); err != nil && !os.IsExist(err) {
-				return fmt.Errorf("failed to link SessionVM plugin: %w", err)
+			if fetch == nil || old != "" {
+				logger.Warn(p.missing, "error", err)
+				continue
 			}
-			logger.Info("linked SessionVM plugin", "src", sessionSrc, "dst", sessionDst)
+			if err := fetch(ctx, p, dst); err != nil {
+				return fmt.Errorf("failed to download %s plugin: %w", p.name, err)
+			}
+			created = append(created, dst)
+			logger.Info("downloaded "+p.name+" plugin", "dst", dst)
+			continue
+		}
+
+		if old != "" {
+			// The search locations can include pluginDir itself
+			if src == old || src == dst {
+				continue
+			}
+			if err := replaceSymlink(dst, src); err != nil {
+				return fmt.Errorf("failed to relink %s plugin: %w", p.name, err)
+			}
+			replaced[dst] = old
+			logger.Info("relinked "+p.name+" plugin", "old", old, "src", src, "dst", dst)
+			continue
 		}
+
+		if err := os.Symlink(src, dst); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to link %s plugin: %w", p.name, err)
+		}
+		created = append(created, dst)
+		logger.Info("linked "+p.name+" plugin", "src", src, "dst", dst)
 	}
 
+	return ctx.Err()
+}
+
+// replaceSymlink atomically points link at target
+func replaceSymlink(link, target string) error {
+	tmp := link + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return err
+	}
 	return nil
 }
 
+// cryptoFlags are the crypto scheme overrides, registered on every command
+// that loads the config so each validates the same settings
+type cryptoFlags struct {
+	signatureScheme *string
+	kemScheme       *string
+	symmetricCipher *string
+}
+
+// addCryptoFlags registers the crypto override flags on fs
+func addCryptoFlags(fs *flag.FlagSet) cryptoFlags {
+	return cryptoFlags{
+		signatureScheme: fs.String("signature-scheme", "", "Override crypto.signatureScheme, e.g. ML-DSA-87"),
+		kemScheme:       fs.String("kem-scheme", "", "Override crypto.kemScheme, e.g. ML-KEM-1024"),
+		symmetricCipher: fs.String("symmetric-cipher", "", "Override crypto.symmetricCipher"),
+	}
+}
+
+// options returns the config options set by the flags
+func (f cryptoFlags) options() *config.Options {
+	return &config.Options{
+		SignatureScheme: *f.signatureScheme,
+		KEMScheme:       *f.kemScheme,
+		SymmetricCipher: *f.symmetricCipher,
+	}
+}
+
+// loadConfig loads the node config, verifying its signature against the
+// public key in keyFile when one is given
+func loadConfig(path, keyFile string, opts *config.Options) (*config.Config, error) {
+	if keyFile == "" {
+		return config.Load(path, opts)
+	}
+	publicKey, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config verification key: %w", err)
+	}
+	return config.LoadVerified(path, publicKey, opts)
+}
+
+// defaultDataDir returns ~/.pars, or FallbackDataDir along with the
+// lookup error when the home directory cannot be resolved (e.g. no HOME
+// in a minimal container)
+func defaultDataDir(userHomeDir func() (string, error)) (string, error) {
+	home, err := userHomeDir()
+	if err != nil {
+		return FallbackDataDir, err
+	}
+	return filepath.Join(home, ".pars"), nil
+}
+
 // findLuxd searches for the luxd binary
 func findLuxd() (string, error) {
 	if path, err := exec.LookPath("luxd"); err == nil {
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/genesis"
+)
+
+func TestRunLuxdCancel(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		minWait time.Duration
+	}{
+		// Exits promptly on SIGTERM
+		{"graceful", "exec sleep 30", 5 * time.Second, 0},
+		// Ignores SIGTERM, so it must be killed after the timeout
+		{"killed", `trap "" TERM; while :; do sleep 0.1; done`, 500 * time.Millisecond, 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan error, 1)
+			go func() {
+				done <- runLuxd(ctx, log.NewNoOpLogger(), sh, []string{"-c", tt.script}, io.Discard, io.Discard, tt.timeout)
+			}()
+
+			// Let the child start before cancelling
+			time.Sleep(200 * time.Millisecond)
+			start := time.Now()
+			cancel()
+
+			select {
+			case <-done:
+				elapsed := time.Since(start)
+				if elapsed < tt.minWait {
+					t.Errorf("child stopped after %v, expected it to survive SIGTERM for %v", elapsed, tt.minWait)
+				}
+			case <-time.After(tt.timeout + 2*time.Second):
+				t.Fatalf("child not terminated within shutdown timeout %v", tt.timeout)
+			}
+		})
+	}
+}
+
+func TestTrackedChains(t *testing.T) {
+	pluginDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pluginDir, EVMID), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	args := buildLuxdArgs(ParsMainnetID, t.TempDir(), pluginDir)
+	if !slices.Contains(args, "--track-chains=C") {
+		t.Errorf("expected only the C-Chain tracked without SessionVM, got %v", args)
+	}
+
+	if err := os.WriteFile(filepath.Join(pluginDir, SessionVMID), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	args = buildLuxdArgs(ParsMainnetID, t.TempDir(), pluginDir)
+	if !slices.Contains(args, "--track-chains=C,S") {
+		t.Errorf("expected C and S chains tracked with SessionVM, got %v", args)
+	}
+}
+
+func TestLuxdLogLevelArgs(t *testing.T) {
+	tests := []struct {
+		level string
+		want  []string
+		valid bool
+	}{
+		{"", nil, true},
+		{"debug", []string{"--log-level=debug"}, true},
+		{"WARN", []string{"--log-level=warn"}, true},
+		{"loud", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, err := luxdLogLevelArgs(tt.level)
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid=%v, got %v", tt.level, tt.valid, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.level, tt.want, got)
+		}
+	}
+}
+
+func TestBootModeArgs(t *testing.T) {
+	const node = "NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg@10.0.0.1:9659"
+
+	bootstrapArgs, err := bootModeArgs(true, false, "")
+	if err != nil {
+		t.Fatalf("bootstrap: unexpected error: %v", err)
+	}
+	joinArgs, err := bootModeArgs(false, true, node)
+	if err != nil {
+		t.Fatalf("join: unexpected error: %v", err)
+	}
+	if slices.Equal(bootstrapArgs, joinArgs) {
+		t.Errorf("expected distinct args, both got %v", joinArgs)
+	}
+	for _, want := range []string{
+		"--bootstrap-ips=10.0.0.1:9659",
+		"--bootstrap-ids=NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg",
+		"--state-sync-ips=10.0.0.1:9659",
+	} {
+		if !slices.Contains(joinArgs, want) {
+			t.Errorf("join: expected %s in %v", want, joinArgs)
+		}
+	}
+
+	if _, err := bootModeArgs(true, true, node); !errors.Is(err, ErrConflictingBootMode) {
+		t.Errorf("expected ErrConflictingBootMode, got %v", err)
+	}
+	for _, bad := range []string{"", "10.0.0.1:9659", "NodeID-bogus@10.0.0.1:9659", "NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg@10.0.0.1"} {
+		if _, err := bootModeArgs(false, true, bad); !errors.Is(err, ErrInvalidBootnode) {
+			t.Errorf("%q: expected ErrInvalidBootnode, got %v", bad, err)
+		}
+	}
+	if args, err := bootModeArgs(false, false, ""); err != nil || args != nil {
+		t.Errorf("expected no args by default, got %v, %v", args, err)
+	}
+}
+
+func TestLinkPluginsCancelled(t *testing.T) {
+	pluginDir := t.TempDir()
+	binDir := t.TempDir()
+	for _, name := range []string{"evm", "sessionvm"} {
+		if err := os.WriteFile(filepath.Join(binDir, name), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	plugins := []pluginLink{
+		{name: "EVM", vmID: EVMID, find: func() (string, error) {
+			return filepath.Join(binDir, "evm"), nil
+		}},
+		// SIGTERM arrives while the second plugin is being located
+		{name: "SessionVM", vmID: SessionVMID, find: func() (string, error) {
+			cancel()
+			return filepath.Join(binDir, "sessionvm"), nil
+		}},
+	}
+
+	if err := linkPlugins(ctx, pluginDir, plugins, true, nil, log.NewNoOpLogger()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no partial links after cancellation, found %v", entries)
+	}
+}
+
+func TestLinkPluginsKeepsExisting(t *testing.T) {
+	pluginDir := t.TempDir()
+	existing := filepath.Join(pluginDir, EVMID)
+	if err := os.WriteFile(existing, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := linkPlugins(ctx, pluginDir, vmPlugins, true, nil, log.NewNoOpLogger()); err == nil {
+		t.Fatal("expected error for cancelled setup")
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("expected pre-existing plugin left in place, got %v", err)
+	}
+}
+
+func TestLinkPluginsRefresh(t *testing.T) {
+	pluginDir := t.TempDir()
+	binDir := t.TempDir()
+	oldBin := filepath.Join(binDir, "evm-v1")
+	newBin := filepath.Join(binDir, "evm-v2")
+	for _, bin := range []string{oldBin, newBin} {
+		if err := os.WriteFile(bin, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(pluginDir, EVMID)
+	if err := os.Symlink(oldBin, link); err != nil {
+		t.Fatal(err)
+	}
+
+	src := newBin
+	plugins := []pluginLink{{name: "EVM", vmID: EVMID, find: func() (string, error) { return src, nil }}}
+	ctx := context.Background()
+
+	if err := linkPlugins(ctx, pluginDir, plugins, false, nil, log.NewNoOpLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.Readlink(link); got != oldBin {
+		t.Errorf("expected link untouched without refresh, got %s", got)
+	}
+
+	if err := linkPlugins(ctx, pluginDir, plugins, true, nil, log.NewNoOpLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.Readlink(link); got != newBin {
+		t.Errorf("expected link repointed to %s, got %s", newBin, got)
+	}
+
+	// A search location inside pluginDir must not link the plugin to itself
+	src = link
+	if err := linkPlugins(ctx, pluginDir, plugins, true, nil, log.NewNoOpLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.Readlink(link); got != newBin {
+		t.Errorf("expected link kept at %s, got %s", newBin, got)
+	}
+}
+
+func TestConsensusChainConfig(t *testing.T) {
+	args := buildLuxdArgs(ParsMainnetID, t.TempDir(), t.TempDir())
+	var content string
+	for _, arg := range args {
+		if v, ok := strings.CutPrefix(arg, "--chain-config-content="); ok {
+			content = v
+		}
+	}
+	var chainConfig map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &chainConfig); err != nil {
+		t.Fatalf("invalid chain config: %v", err)
+	}
+	if _, ok := chainConfig["pars-consensus"]; ok {
+		t.Error("expected no consensus settings in the chain config, luxd reads none")
+	}
+
+	consensus := config.Default().Consensus
+	if got := unappliedConsensus(consensus); len(got) != 0 {
+		t.Errorf("expected defaults reported as applied, got %v", got)
+	}
+	consensus.BlockTimeMs = 500
+	consensus.Validators.MaxCount = 21
+	want := []string{"consensus.blockTimeMs", "consensus.validators.maxCount"}
+	if got := unappliedConsensus(consensus); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCryptoFlagsDefaultPath(t *testing.T) {
+	// parsd --signature-scheme=ML-DSA-87 with every other setting default
+	fs := flag.NewFlagSet("parsd", flag.ContinueOnError)
+	overrides := addCryptoFlags(fs)
+	if err := fs.Parse([]string{"--signature-scheme=ML-DSA-87"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load("", overrides.options())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Crypto.SignatureScheme != "ML-DSA-87" || !cfg.Crypto.GPUEnabled {
+		t.Errorf("expected ML-DSA-87 with GPU left at its default, got %s, gpu %v",
+			cfg.Crypto.SignatureScheme, cfg.Crypto.GPUEnabled)
+	}
+}
+
+func TestDefaultDataDir(t *testing.T) {
+	got, err := defaultDataDir(func() (string, error) { return "/home/pars", nil })
+	if err != nil || got != filepath.Join("/home/pars", ".pars") {
+		t.Errorf("expected ~/.pars, got %q (%v)", got, err)
+	}
+
+	got, err = defaultDataDir(func() (string, error) { return "", errors.New("$HOME is not defined") })
+	if err == nil {
+		t.Error("expected the home lookup error to be reported")
+	}
+	if got != FallbackDataDir {
+		t.Errorf("expected fallback %s, got %q", FallbackDataDir, got)
+	}
+}
+
+func TestWriteEmbeddedGenesis(t *testing.T) {
+	for name, networkID := range genesisNetworks {
+		path := filepath.Join(t.TempDir(), "genesis.json")
+		if err := writeEmbeddedGenesis(path, name, 7070); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s: expected mode 0600, got %o", name, perm)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := genesis.Validate(data, networkID, 7070); err != nil {
+			t.Errorf("%s: written genesis invalid: %v", name, err)
+		}
+	}
+
+	err := writeEmbeddedGenesis(filepath.Join(t.TempDir(), "genesis.json"), "custom", 7070)
+	if err == nil || !strings.Contains(err.Error(), "devnet, mainnet, testnet") {
+		t.Errorf("expected error listing supported networks, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/luxfi/ids"
+)
+
+// pluginCheck is the result of checking one VM plugin
+type pluginCheck struct {
+	Name string
+	VMID string
+	Path string // Resolved plugin binary, empty if not found
+	Err  error  // Why the plugin is unusable, nil if it passed
+}
+
+// runPlugins implements `parsd plugins check`
+func runPlugins(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: parsd plugins check [--data-dir path]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("plugins check", flag.ContinueOnError)
+	dir := fs.String("data-dir", "", "Data directory (default: ~/.pars)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	dataPath := *dir
+	if dataPath == "" {
+		dataPath, _ = defaultDataDir(os.UserHomeDir)
+	}
+
+	results := checkPlugins(filepath.Join(dataPath, "plugins"), vmPlugins)
+	printPluginChecks(os.Stdout, results)
+
+	for _, r := range results {
+		if r.Err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkPlugins decodes each plugin's VM ID and resolves its binary, first
+// as linked into pluginDir and then from the plugin's search locations
+func checkPlugins(pluginDir string, plugins []pluginLink) []pluginCheck {
+	results := make([]pluginCheck, 0, len(plugins))
+	for _, p := range plugins {
+		r := pluginCheck{Name: p.name, VMID: p.vmID}
+		if _, err := ids.FromString(p.vmID); err != nil {
+			r.Err = fmt.Errorf("invalid VM ID: %w", err)
+			results = append(results, r)
+			continue
+		}
+
+		path := filepath.Join(pluginDir, p.vmID)
+		if _, err := os.Stat(path); err != nil {
+			found, findErr := p.find()
+			if findErr != nil {
+				r.Err = fmt.Errorf("binary not found: %w", findErr)
+				results = append(results, r)
+				continue
+			}
+			path = found
+		}
+		r.Path = path
+		results = append(results, r)
+	}
+	return results
+}
+
+func printPluginChecks(w io.Writer, results []pluginCheck) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "plugin\tvm id\tstatus\tpath")
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, r.VMID, status, r.Path)
+	}
+	tw.Flush()
+}
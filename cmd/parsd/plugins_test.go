@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckPlugins(t *testing.T) {
+	pluginDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pluginDir, SessionVMID), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	notFound := func() (string, error) { return "", errors.New("not found") }
+	plugins := []pluginLink{
+		{name: "SessionVM", vmID: SessionVMID, find: notFound},
+		{name: "Typo", vmID: "speKUgLBX6WRD5cfGeEfLa43LxTXUBckvtv4td6F3eTXvRP4", find: notFound},
+		{name: "NotBase58", vmID: "0OIl", find: notFound},
+		{name: "EVM", vmID: EVMID, find: notFound},
+	}
+
+	results := checkPlugins(pluginDir, plugins)
+	if len(results) != len(plugins) {
+		t.Fatalf("expected %d results, got %d", len(plugins), len(results))
+	}
+
+	if r := results[0]; r.Err != nil || r.Path != filepath.Join(pluginDir, SessionVMID) {
+		t.Errorf("expected SessionVM to pass, got path=%q err=%v", r.Path, r.Err)
+	}
+	for _, r := range results[1:3] {
+		if r.Err == nil {
+			t.Errorf("%s: expected malformed VM ID to be flagged", r.Name)
+		}
+	}
+	if r := results[3]; r.Err == nil {
+		t.Error("expected EVM with missing binary to be flagged")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrPortInUse   = errors.New("port already in use")
+	ErrInvalidHost = errors.New("invalid host")
+)
+
+// hostnameLabel is one dot-separated label of an RFC 1123 hostname
+var hostnameLabel = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// listenAddr is an address luxd listens on, named by its parsd flag
+// prefix ("http" for --http-host/--http-port). An empty host leaves the
+// interface to luxd's default.
+type listenAddr struct {
+	name string
+	host string
+	port int
+}
+
+// luxdArgs returns the luxd flags for the address
+func (l listenAddr) luxdArgs() []string {
+	args := []string{fmt.Sprintf("--%s-port=%d", l.name, l.port)}
+	if l.host != "" {
+		args = append(args, fmt.Sprintf("--%s-host=%s", l.name, l.host))
+	}
+	return args
+}
+
+// validateHost accepts an IP address or an RFC 1123 hostname
+func validateHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if len(host) == 0 || len(host) > 253 {
+		return fmt.Errorf("%w: %q", ErrInvalidHost, host)
+	}
+	start := 0
+	for i := 0; i <= len(host); i++ {
+		if i < len(host) && host[i] != '.' {
+			continue
+		}
+		if !hostnameLabel.MatchString(host[start:i]) {
+			return fmt.Errorf("%w: %q", ErrInvalidHost, host)
+		}
+		start = i + 1
+	}
+	return nil
+}
+
+// checkPortsFree test-binds each address (all interfaces when no host is
+// set) so that a conflict is reported up front rather than as a bind
+// error inside luxd
+func checkPortsFree(addrs []listenAddr) error {
+	for _, a := range addrs {
+		ln, err := net.Listen("tcp", net.JoinHostPort(a.host, strconv.Itoa(a.port)))
+		if err != nil {
+			return fmt.Errorf("%w: --%s-port %d: %w", ErrPortInUse, a.name, a.port, err)
+		}
+		ln.Close()
+	}
+	return nil
+}
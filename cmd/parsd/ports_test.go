@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckPortsFree(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	busy := ln.Addr().(*net.TCPAddr).Port
+
+	free, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	freePort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	if err := checkPortsFree([]listenAddr{{name: "http", port: freePort}}); err != nil {
+		t.Errorf("expected free port to pass, got %v", err)
+	}
+
+	err = checkPortsFree([]listenAddr{{name: "http", port: freePort}, {name: "staking", port: busy}})
+	if !errors.Is(err, ErrPortInUse) {
+		t.Fatalf("expected ErrPortInUse, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "--staking-port") {
+		t.Errorf("expected error naming --staking-port, got %v", err)
+	}
+}
+
+func TestListenAddrArgs(t *testing.T) {
+	tests := []struct {
+		addr listenAddr
+		want []string
+	}{
+		{listenAddr{name: "http", port: 9660}, []string{"--http-port=9660"}},
+		{listenAddr{name: "http", host: "127.0.0.1", port: 9660}, []string{"--http-port=9660", "--http-host=127.0.0.1"}},
+		{listenAddr{name: "staking", host: "node1.pars.network", port: 9659}, []string{"--staking-port=9659", "--staking-host=node1.pars.network"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.addr.luxdArgs(); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v: expected %v, got %v", tt.addr, tt.want, got)
+		}
+	}
+}
+
+func TestValidateHost(t *testing.T) {
+	tests := []struct {
+		host  string
+		valid bool
+	}{
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"localhost", true},
+		{"node-1.pars.network", true},
+		{"", false},
+		{"127.0.0.1:9660", false},
+		{"-node.example", false},
+		{"node..example", false},
+		{"node_1.example", false},
+	}
+
+	for _, tt := range tests {
+		err := validateHost(tt.host)
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid=%v, got %v", tt.host, tt.valid, err)
+		}
+	}
+}
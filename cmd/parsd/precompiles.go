@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/vm"
+)
+
+// precompileChecker is the part of vm.EVM the precompile check uses
+type precompileChecker interface {
+	CheckPrecompiles(ctx context.Context) ([]string, error)
+}
+
+// withCChainRPC returns cfg with RPCEndpoint defaulting to the C-Chain
+// RPC of the luxd HTTP API at endpoint, e.g. http://127.0.0.1:9660, so
+// the check queries the same luxd whose health gates it
+func withCChainRPC(cfg config.EVMConfig, endpoint string) config.EVMConfig {
+	if cfg.RPCEndpoint == "" {
+		cfg.RPCEndpoint = strings.TrimSuffix(endpoint, "/") + "/ext/bc/" + CChainAlias + "/rpc"
+	}
+	return cfg
+}
+
+// precompileCheck cross-checks the configured precompiles against the EVM
+// plugin once luxd reports the C-Chain healthy, since only then does the
+// chain serve RPC. It checks once, retrying only failed probes.
+type precompileCheck struct {
+	evm    precompileChecker
+	chain  vm.HealthChecker
+	logger log.Logger
+	onFail func() // Called when a missing precompile fails the check
+
+	mu     sync.Mutex
+	status vm.HealthStatus
+}
+
+func newPrecompileCheck(evm precompileChecker, chain vm.HealthChecker, logger log.Logger, onFail func()) *precompileCheck {
+	return &precompileCheck{
+		evm:    evm,
+		chain:  chain,
+		logger: logger,
+		onFail: onFail,
+		status: vm.HealthStatus{Healthy: true, Message: "waiting for the C-Chain"},
+	}
+}
+
+// run polls every interval until the check is done or ctx is done
+func (p *precompileCheck) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if p.check(ctx) {
+				return
+			}
+		}
+	}
+}
+
+// check runs the precompile check if the C-Chain is healthy, reporting
+// whether it is done
+func (p *precompileCheck) check(ctx context.Context) bool {
+	if !p.chain.Health().Healthy {
+		return false
+	}
+
+	missing, err := p.evm.CheckPrecompiles(ctx)
+	switch {
+	case errors.Is(err, vm.ErrMissingPrecompile):
+		p.logger.Error("configured precompiles missing from the EVM plugin", "missing", missing)
+		p.setStatus(vm.HealthStatus{Healthy: false, Message: err.Error()})
+		if p.onFail != nil {
+			p.onFail()
+		}
+		return true
+	case err != nil:
+		p.logger.Debug("precompile check failed, retrying", "error", err)
+		return false
+	case len(missing) > 0:
+		p.logger.Warn("configured precompiles missing from the EVM plugin", "missing", missing)
+		p.setStatus(vm.HealthStatus{Healthy: true, Message: fmt.Sprintf("missing: %s", strings.Join(missing, ", "))})
+	default:
+		p.setStatus(vm.HealthStatus{Healthy: true})
+	}
+	return true
+}
+
+func (p *precompileCheck) setStatus(status vm.HealthStatus) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = status
+}
+
+// Health implements vm.HealthChecker: unhealthy only once the check has
+// failed
+func (p *precompileCheck) Health() vm.HealthStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/vm"
+)
+
+// fakePrecompiles returns a fixed check result
+type fakePrecompiles struct {
+	missing []string
+	err     error
+	calls   int
+}
+
+func (f *fakePrecompiles) CheckPrecompiles(context.Context) ([]string, error) {
+	f.calls++
+	return f.missing, f.err
+}
+
+func TestPrecompileCheck(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		evm     *fakePrecompiles
+		healthy bool
+		failed  bool
+	}{
+		{"all present", &fakePrecompiles{}, true, false},
+		{"missing warns", &fakePrecompiles{missing: []string{"fhe"}}, true, false},
+		{"missing fails", &fakePrecompiles{missing: []string{"fhe"}, err: fmt.Errorf("%w: fhe", vm.ErrMissingPrecompile)}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakeHealth{status: vm.HealthStatus{Message: "not bootstrapped"}}
+			failed := false
+			p := newPrecompileCheck(tt.evm, chain, log.NewNoOpLogger(), func() { failed = true })
+
+			// Nothing is probed before the C-Chain serves RPC
+			if p.check(ctx) || tt.evm.calls != 0 {
+				t.Fatal("expected no check before the C-Chain is healthy")
+			}
+
+			chain.status = vm.HealthStatus{Healthy: true}
+			if !p.check(ctx) {
+				t.Fatal("expected the check done once the C-Chain is healthy")
+			}
+			if got := p.Health().Healthy; got != tt.healthy {
+				t.Errorf("expected healthy %v, got %+v", tt.healthy, p.Health())
+			}
+			if failed != tt.failed {
+				t.Errorf("expected onFail called %v, got %v", tt.failed, failed)
+			}
+		})
+	}
+
+	// A failed probe is retried
+	evm := &fakePrecompiles{err: errors.New("connection refused")}
+	p := newPrecompileCheck(evm, &fakeHealth{status: vm.HealthStatus{Healthy: true}}, log.NewNoOpLogger(), nil)
+	if p.check(ctx) || !p.Health().Healthy {
+		t.Errorf("expected a failed probe retried, got %+v", p.Health())
+	}
+}
+
+func TestPrecompileCheckUsesLuxdEndpoint(t *testing.T) {
+	var rpcCalls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ext/health":
+			w.Write([]byte(`{"checks": {"C": {}}, "healthy": true}`))
+		case "/ext/bc/C/rpc":
+			rpcCalls++
+			var req struct {
+				ID uint64 `json:"id"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %d, "result": "0x01"}`, req.ID)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	// httptest listens on a random port, never luxd's default
+	cfg := config.Default()
+	cfg.EVM.PrecompileCheck = "fail"
+	evm, err := vm.NewEVM(withCChainRPC(cfg.EVM, srv.URL), cfg.Features)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := evm.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	p := newPrecompileCheck(evm, vm.NewChainHealth(srv.URL, CChainAlias), log.NewNoOpLogger(), nil)
+	if !p.check(context.Background()) {
+		t.Fatal("expected the check done")
+	}
+	if status := p.Health(); !status.Healthy || status.Message != "" {
+		t.Errorf("expected every precompile found, got %+v", status)
+	}
+	if rpcCalls == 0 {
+		t.Error("expected the precompiles probed through the luxd endpoint")
+	}
+
+	// An explicit evm.rpcEndpoint is kept
+	cfg.EVM.RPCEndpoint = "http://rpc.example:8545"
+	if got := withCChainRPC(cfg.EVM, srv.URL).RPCEndpoint; got != cfg.EVM.RPCEndpoint {
+		t.Errorf("expected %s kept, got %s", cfg.EVM.RPCEndpoint, got)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/parsdao/node/version"
+)
+
+// luxdVersionTimeout bounds `luxd --version`
+const luxdVersionTimeout = 5 * time.Second
+
+// runVersion implements `parsd version` and --version
+func runVersion(args []string) int {
+	printVersion(os.Stdout, findLuxd, luxdVersion)
+	return 0
+}
+
+// printVersion writes parsd's build metadata and the luxd it would run.
+// A missing or failing luxd is reported, not treated as an error.
+func printVersion(w io.Writer, find func() (string, error), luxdVer func(path string) (string, error)) {
+	fmt.Fprintf(w, "parsd:  %s\n", version.Version)
+	fmt.Fprintf(w, "commit: %s\n", version.Commit)
+	fmt.Fprintf(w, "built:  %s\n", version.Date)
+	fmt.Fprintf(w, "go:     %s\n", version.GoVersion())
+
+	path, err := find()
+	if err != nil {
+		fmt.Fprintf(w, "luxd:   not found (%v)\n", err)
+		return
+	}
+	v, err := luxdVer(path)
+	if err != nil {
+		v = fmt.Sprintf("unknown (%v)", err)
+	}
+	fmt.Fprintf(w, "luxd:   %s (%s)\n", path, v)
+}
+
+// luxdVersion asks the luxd binary at path for its version
+func luxdVersion(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), luxdVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parsdao/node/version"
+)
+
+func TestPrintVersion(t *testing.T) {
+	for name, p := range map[string]*string{"Version": &version.Version, "Commit": &version.Commit, "Date": &version.Date} {
+		old := *p
+		t.Cleanup(func() { *p = old })
+		*p = "stamped-" + name
+	}
+
+	var out bytes.Buffer
+	printVersion(&out,
+		func() (string, error) { return "/opt/lux/luxd", nil },
+		func(path string) (string, error) { return "luxd/1.13.0", nil },
+	)
+	for _, want := range []string{
+		"stamped-Version", "stamped-Commit", "stamped-Date",
+		version.GoVersion(), "/opt/lux/luxd (luxd/1.13.0)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	printVersion(&out,
+		func() (string, error) { return "", errors.New("luxd not found") },
+		func(path string) (string, error) { t.Error("unexpected luxd call"); return "", nil },
+	)
+	if !strings.Contains(out.String(), "luxd:   not found") {
+		t.Errorf("expected missing luxd reported, got:\n%s", out.String())
+	}
+}
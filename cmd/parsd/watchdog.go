@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/vm"
+)
+
+// DefaultWatchdogPoll is how often the watchdog checks luxd's health
+const DefaultWatchdogPoll = 15 * time.Second
+
+// watchdog raises an alarm when luxd reports the C-Chain unhealthy for
+// longer than timeout. It watches liveness rather than block height:
+// Lux EVM chains produce no empty blocks, so an idle chain is not stalled.
+// Timing starts once the chain is first healthy, so a node still
+// bootstrapping is not reported as stalled.
+type watchdog struct {
+	source  vm.HealthChecker
+	timeout time.Duration
+	logger  log.Logger
+	onStall func() // Called once per stall, e.g. to restart luxd
+
+	// now is the watchdog clock, replaceable in tests
+	now func() time.Time
+
+	mu          sync.Mutex
+	lastHealthy time.Time // Zero until the chain is first healthy
+	reason      string    // Why luxd last reported the chain unhealthy
+	stalled     bool
+
+	// Stalls detected so far
+	stalls atomic.Uint64
+}
+
+func newWatchdog(source vm.HealthChecker, timeout time.Duration, logger log.Logger, onStall func()) *watchdog {
+	return &watchdog{
+		source:  source,
+		timeout: timeout,
+		logger:  logger,
+		onStall: onStall,
+		now:     time.Now,
+	}
+}
+
+// run polls every interval until ctx is done
+func (w *watchdog) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check reads the chain health once and updates the stall state
+func (w *watchdog) check() {
+	status := w.source.Health()
+
+	w.mu.Lock()
+	now := w.now()
+	if status.Healthy {
+		if w.stalled {
+			w.logger.Info("watchdog: luxd healthy again")
+		}
+		w.lastHealthy, w.reason, w.stalled = now, "", false
+	} else {
+		w.reason = status.Message
+		w.logger.Debug("watchdog: luxd reports the chain unhealthy", "reason", status.Message)
+	}
+
+	trip := !w.stalled && !w.lastHealthy.IsZero() && now.Sub(w.lastHealthy) > w.timeout
+	if trip {
+		w.stalled = true
+		w.stalls.Add(1)
+		w.logger.Error("WATCHDOG: luxd has been unhealthy past the stall timeout",
+			"reason", w.reason,
+			"since", w.lastHealthy,
+			"timeout", w.timeout,
+			"stalls", w.stalls.Load(),
+		)
+	}
+	w.mu.Unlock()
+
+	if trip && w.onStall != nil {
+		w.onStall()
+	}
+}
+
+// Health implements vm.HealthChecker: unhealthy while stalled
+func (w *watchdog) Health() vm.HealthStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.stalled {
+		return vm.HealthStatus{Healthy: true}
+	}
+	return vm.HealthStatus{
+		Healthy: false,
+		Message: fmt.Sprintf("luxd unhealthy since %s: %s", w.lastHealthy.Format(time.RFC3339), w.reason),
+	}
+}
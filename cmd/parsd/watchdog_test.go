@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luxfi/log"
+
+	"github.com/parsdao/node/vm"
+)
+
+// fakeHealth returns a fixed status
+type fakeHealth struct {
+	status vm.HealthStatus
+}
+
+func (f *fakeHealth) Health() vm.HealthStatus {
+	return f.status
+}
+
+func TestWatchdogStall(t *testing.T) {
+	source := &fakeHealth{status: vm.HealthStatus{Message: "not bootstrapped"}}
+	stalls := 0
+	w := newWatchdog(source, time.Minute, log.NewNoOpLogger(), func() { stalls++ })
+	now := time.Unix(1700000000, 0)
+	w.now = func() time.Time { return now }
+
+	// A node still bootstrapping is not stalled
+	now = now.Add(time.Hour)
+	w.check()
+	if !w.Health().Healthy {
+		t.Fatal("expected healthy before the chain is first healthy")
+	}
+
+	// A healthy chain with no new blocks is idle, not stalled
+	source.status = vm.HealthStatus{Healthy: true}
+	for range 5 {
+		now = now.Add(time.Minute)
+		w.check()
+	}
+	if !w.Health().Healthy {
+		t.Fatal("expected an idle chain healthy")
+	}
+
+	source.status = vm.HealthStatus{Message: "chain not responding"}
+	now = now.Add(30 * time.Second)
+	w.check()
+	if !w.Health().Healthy {
+		t.Fatal("expected healthy within the timeout")
+	}
+
+	now = now.Add(time.Minute)
+	w.check()
+	w.check()
+	status := w.Health()
+	if status.Healthy || status.Message == "" {
+		t.Errorf("expected unhealthy with a reason, got %+v", status)
+	}
+	if stalls != 1 || w.stalls.Load() != 1 {
+		t.Errorf("expected one stall reported, got %d (counter %d)", stalls, w.stalls.Load())
+	}
+
+	source.status = vm.HealthStatus{Healthy: true}
+	w.check()
+	if !w.Health().Healthy {
+		t.Error("expected healthy once luxd recovered")
+	}
+}
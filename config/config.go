@@ -3,9 +3,14 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // Mode defines the network mode
@@ -16,14 +21,24 @@ const (
 	ModeL2 Mode = "l2" // L2 rollup settling on Lux
 )
 
-// Options are command-line options
+// Modes lists the supported network modes
+var Modes = []string{string(ModeL1), string(ModeL2)}
+
+// Options are command-line options. Zero values and nil pointers leave
+// the file or default setting in place.
 type Options struct {
 	Mode       Mode
+	NetworkID  uint32
 	DataDir    string
 	RPCAddr    string
 	P2PAddr    string
-	WarpEnable bool
-	GPUEnable  bool
+	WarpEnable *bool
+	GPUEnable  *bool
+
+	// Crypto scheme overrides
+	SignatureScheme string
+	KEMScheme       string
+	SymmetricCipher string
 }
 
 // Config is the full node configuration
@@ -51,6 +66,9 @@ type Config struct {
 
 	// Consensus configuration
 	Consensus ConsensusConfig `json:"consensus"`
+
+	// Experimental feature flags, all off by default
+	Features Features `json:"features"`
 }
 
 // NetworkConfig defines network settings
@@ -68,6 +86,11 @@ type EVMConfig struct {
 	ChainID     uint64 `json:"chainId"`
 	GasLimit    uint64 `json:"gasLimit"`
 	GenesisPath string `json:"genesisPath"`
+	RPCEndpoint string `json:"rpcEndpoint"` // C-Chain JSON-RPC URL; parsd uses luxd's own when empty
+
+	// PrecompileCheck is what to do when a configured precompile is absent
+	// from the EVM plugin: "off", "warn" or "fail"
+	PrecompileCheck string `json:"precompileCheck"`
 
 	// PQ Precompiles
 	Precompiles PrecompileConfig `json:"precompiles"`
@@ -82,6 +105,26 @@ type PrecompileConfig struct {
 	FHE      string `json:"fhe"`      // 0x0800 - Fully homomorphic encryption
 }
 
+// precompileAddr matches a short (0x0601) or full 20-byte hex address
+var precompileAddr = regexp.MustCompile(`^0[xX][0-9a-fA-F]{1,40}$`)
+
+// validate checks that each configured precompile address is hex. An
+// empty address leaves the precompile unconfigured.
+func (p PrecompileConfig) validate() error {
+	for _, a := range []struct{ name, addr string }{
+		{"mldsa", p.MLDSA},
+		{"mlkem", p.MLKEM},
+		{"bls", p.BLS},
+		{"ringtail", p.Ringtail},
+		{"fhe", p.FHE},
+	} {
+		if a.addr != "" && !precompileAddr.MatchString(a.addr) {
+			return fmt.Errorf("invalid evm.precompiles.%s address %q", a.name, a.addr)
+		}
+	}
+	return nil
+}
+
 // ParsConfig defines Pars messaging settings
 type ParsConfig struct {
 	Enabled bool `json:"enabled"`
@@ -94,6 +137,74 @@ type ParsConfig struct {
 
 	// Session management
 	Session SessionConfig `json:"session"`
+
+	// Message padding to resist traffic analysis
+	Padding PaddingConfig `json:"padding"`
+
+	// Message event journal for debugging delivery
+	Journal JournalConfig `json:"journal"`
+
+	// Node identity keys, created on first start; Load defaults it under
+	// the data dir
+	IdentityFile string `json:"identityFile"`
+
+	// Allowed message TTLs; Load fills in the network default when unset
+	TTL TTLBounds `json:"ttl"`
+
+	// Plaintext compression before encryption; one of Compressions
+	Compression string `json:"compression"`
+
+	// Goroutines available to background message work; more work queues
+	MaxWorkers int `json:"maxWorkers"`
+
+	// Encoding of stored and transmitted messages; one of Serializations
+	Serialization string `json:"serialization"`
+
+	// Seconds a message timestamp may run ahead of local time; 0 disables
+	MaxClockSkew int64 `json:"maxClockSkew"`
+
+	// Holding area for messages that exhaust their send attempts
+	DeadLetter DeadLetterConfig `json:"deadLetter"`
+}
+
+// DeadLetterConfig defines retry limits and the dead-letter queue
+type DeadLetterConfig struct {
+	MaxAttempts      int   `json:"maxAttempts"`      // Send attempts before a message is dead-lettered
+	InitialBackoffMs int64 `json:"initialBackoffMs"` // Delay before the first retry
+	MaxBackoffMs     int64 `json:"maxBackoffMs"`     // Ceiling for the doubling retry delay
+	MaxEntries       int   `json:"maxEntries"`       // Oldest entries dropped beyond this, 0 for no limit
+	Retention        int64 `json:"retention"`        // Seconds entries are kept, 0 forever
+}
+
+// Compressions lists the supported message compression algorithms
+var Compressions = []string{"none", "gzip", "zstd"}
+
+// Serializations lists the supported message encodings: json for
+// debugging, cbor for compact production use
+var Serializations = []string{"json", "cbor"}
+
+// TTLBounds limits message TTLs in seconds. A zero Max means no ceiling.
+type TTLBounds struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// networkTTLBounds are the default TTL bounds by network ID. Devnet allows
+// very short TTLs for testing expiry; mainnet keeps a floor so messages
+// survive ordinary recipient downtime.
+var networkTTLBounds = map[uint32]TTLBounds{
+	7070: {Min: 60 * 60, Max: 30 * 24 * 60 * 60}, // mainnet: 1 hour to 30 days
+	7071: {Min: 60, Max: 30 * 24 * 60 * 60},      // testnet: 1 minute to 30 days
+	7072: {Min: 1, Max: 30 * 24 * 60 * 60},       // devnet: 1 second to 30 days
+}
+
+// DefaultTTLBounds returns the default TTL bounds for networkID. Unknown
+// networks get the mainnet bounds.
+func DefaultTTLBounds(networkID uint32) TTLBounds {
+	if b, ok := networkTTLBounds[networkID]; ok {
+		return b
+	}
+	return networkTTLBounds[7070]
 }
 
 // StorageConfig defines storage node settings
@@ -102,18 +213,46 @@ type StorageConfig struct {
 	MaxSize       uint64 `json:"maxSize"` // Max storage in bytes
 	RetentionDays int    `json:"retentionDays"`
 	DataDir       string `json:"dataDir"`
+	MinFreeBytes  uint64 `json:"minFreeBytes"` // Refuse writes below this much free disk
+	Role          string `json:"role"`         // One of StorageRoles; followers are read-only
+	GCInterval    int64  `json:"gcInterval"`   // Seconds between expiry sweeps, 0 disables
+
+	// A follower mirrors the primary's storage directory, e.g. on a shared
+	// or synced volume, every ReplicateInterval seconds
+	PrimaryDir        string `json:"primaryDir"`
+	ReplicateInterval int64  `json:"replicateInterval"`
 }
 
+// StorageRoles lists the supported storage node roles
+var StorageRoles = []string{"primary", "follower"}
+
 // OnionConfig defines onion routing settings
 type OnionConfig struct {
 	Enabled  bool `json:"enabled"`
 	HopCount int  `json:"hopCount"` // Number of routing hops
 }
 
+// PaddingConfig defines message padding settings
+type PaddingConfig struct {
+	Enabled   bool `json:"enabled"`
+	MaxBucket int  `json:"maxBucket"` // Largest power-of-two bucket in bytes
+}
+
+// JournalConfig defines message journal settings
+type JournalConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"maxSizeMB"` // Rotate after this many megabytes
+	MaxBackups int    `json:"maxBackups"`
+}
+
 // SessionConfig defines session management settings
 type SessionConfig struct {
-	IDPrefix        string `json:"idPrefix"` // "07" for PQ sessions
+	IDPrefix        string `json:"idPrefix"`   // "07" for PQ sessions
+	IDEncoding      string `json:"idEncoding"` // One of SessionIDEncodings
 	KeyRotationDays int    `json:"keyRotationDays"`
+	IdleTimeout     int64  `json:"idleTimeout"` // Seconds without activity before auto-close, 0 disables
+	MaxSessions     int    `json:"maxSessions"` // Open sessions allowed at once, 0 for no limit
 }
 
 // WarpConfig defines cross-chain settings
@@ -121,6 +260,17 @@ type WarpConfig struct {
 	Enabled       bool     `json:"enabled"`
 	LuxEndpoint   string   `json:"luxEndpoint"`
 	AllowedChains []string `json:"allowedChains"`
+
+	// Health probing of LuxEndpoint and backoff while it is unreachable
+	Reconnect ReconnectConfig `json:"reconnect"`
+}
+
+// ReconnectConfig defines the warp endpoint circuit breaker
+type ReconnectConfig struct {
+	ProbeIntervalMs  int64 `json:"probeIntervalMs"`  // Between probes while connected
+	FailureThreshold int   `json:"failureThreshold"` // Consecutive failed probes before disconnecting
+	InitialBackoffMs int64 `json:"initialBackoffMs"` // First retry delay once disconnected
+	MaxBackoffMs     int64 `json:"maxBackoffMs"`     // Ceiling for the doubling retry delay
 }
 
 // CryptoConfig defines cryptographic settings
@@ -130,19 +280,45 @@ type CryptoConfig struct {
 	// Signature scheme (ML-DSA-65 for NIST Level 3)
 	SignatureScheme string `json:"signatureScheme"`
 
-	// KEM scheme (ML-KEM-768 for NIST Level 3)
+	// KEM scheme of the node identity (ML-KEM-768 for NIST Level 3); one
+	// of KEMSchemes. Messages are sealed with the scheme of the recipient.
 	KEMScheme string `json:"kemScheme"`
 
+	// Message AEAD; one of SymmetricCiphers
+	SymmetricCipher string `json:"symmetricCipher"`
+
 	// Threshold signatures (Ringtail - Ring-LWE based)
 	ThresholdScheme string `json:"thresholdScheme"`
 }
 
+// KEMSchemes lists the supported key encapsulation schemes
+var KEMSchemes = []string{"ML-KEM-512", "ML-KEM-768", "ML-KEM-1024"}
+
+// SignatureSchemes lists the supported signature schemes
+var SignatureSchemes = []string{"ML-DSA-44", "ML-DSA-65", "ML-DSA-87"}
+
+// SymmetricCiphers lists the supported message AEADs
+var SymmetricCiphers = []string{"XChaCha20-Poly1305"}
+
+// SessionIDEncodings lists the supported session ID encodings: hex is
+// "07" + hex digest, bech32 is human friendly with a "pars1" prefix
+var SessionIDEncodings = []string{"hex", "bech32"}
+
+// PrecompileChecks lists the supported precompile check modes
+var PrecompileChecks = []string{"off", "warn", "fail"}
+
+// Bounds on consensus.blockTimeMs
+const (
+	MinBlockTimeMs = 100
+	MaxBlockTimeMs = 60 * 1000
+)
+
 // ConsensusConfig defines consensus settings
 type ConsensusConfig struct {
 	// Quasar consensus configuration
 	Engine string `json:"engine"` // "quasar"
 
-	// Block time in milliseconds
+	// Block time in milliseconds, within [MinBlockTimeMs, MaxBlockTimeMs]
 	BlockTimeMs uint64 `json:"blockTimeMs"`
 
 	// Validator configuration
@@ -167,9 +343,10 @@ func Default() *Config {
 			NetworkID: 7070,
 		},
 		EVM: EVMConfig{
-			Enabled:  true,
-			ChainID:  7070,
-			GasLimit: 30000000,
+			Enabled:         true,
+			ChainID:         7070,
+			GasLimit:        30000000,
+			PrecompileCheck: "warn",
 			Precompiles: PrecompileConfig{
 				MLDSA:    "0x0601",
 				MLKEM:    "0x0603",
@@ -184,6 +361,11 @@ func Default() *Config {
 				Enabled:       true,
 				MaxSize:       10 * 1024 * 1024 * 1024, // 10GB
 				RetentionDays: 30,
+				MinFreeBytes:  1024 * 1024 * 1024, // 1GB
+				Role:          "primary",
+				GCInterval:    5 * 60, // 5 minutes
+
+				ReplicateInterval: 5,
 			},
 			Onion: OnionConfig{
 				Enabled:  true,
@@ -191,17 +373,46 @@ func Default() *Config {
 			},
 			Session: SessionConfig{
 				IDPrefix:        "07", // PQ session ID prefix
+				IDEncoding:      "hex",
 				KeyRotationDays: 90,
+				IdleTimeout:     24 * 60 * 60, // 1 day
+				MaxSessions:     10000,
+			},
+			Padding: PaddingConfig{
+				Enabled:   true,
+				MaxBucket: 64 * 1024, // 64KB
+			},
+			Compression:   "zstd",
+			MaxWorkers:    64,
+			Serialization: "cbor",
+			MaxClockSkew:  5 * 60, // 5 minutes
+			DeadLetter: DeadLetterConfig{
+				MaxAttempts:      3,
+				InitialBackoffMs: 500,
+				MaxBackoffMs:     30 * 1000,
+				MaxEntries:       1000,
+				Retention:        7 * 24 * 60 * 60, // 7 days
+			},
+			Journal: JournalConfig{
+				MaxSizeMB:  100,
+				MaxBackups: 5,
 			},
 		},
 		Warp: WarpConfig{
 			Enabled:     true,
 			LuxEndpoint: "https://api.lux.network",
+			Reconnect: ReconnectConfig{
+				ProbeIntervalMs:  10 * 1000, // 10s
+				FailureThreshold: 3,
+				InitialBackoffMs: 1000,      // 1s
+				MaxBackoffMs:     60 * 1000, // 1 minute
+			},
 		},
 		Crypto: CryptoConfig{
 			GPUEnabled:      true,
 			SignatureScheme: "ML-DSA-65",
 			KEMScheme:       "ML-KEM-768",
+			SymmetricCipher: "XChaCha20-Poly1305",
 			ThresholdScheme: "Ringtail",
 		},
 		Consensus: ConsensusConfig{
@@ -215,8 +426,22 @@ func Default() *Config {
 	}
 }
 
-// Load loads configuration from file and applies options
+// Load loads configuration from file, then applies PARS_* environment
+// overrides (see envOverrides) and options, in increasing precedence
 func Load(path string, opts *Options) (*Config, error) {
+	return load(path, nil, opts)
+}
+
+// LoadVerified is Load for a config file that must carry a detached
+// signature by publicKey; see SignatureSuffix
+func LoadVerified(path string, publicKey []byte, opts *Options) (*Config, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: no config file to verify", ErrConfigSignature)
+	}
+	return load(path, publicKey, opts)
+}
+
+func load(path string, publicKey []byte, opts *Options) (*Config, error) {
 	cfg := Default()
 
 	// Load from file if provided
@@ -225,9 +450,29 @@ func Load(path string, opts *Options) (*Config, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to read config file: %w", err)
 		}
+		if publicKey != nil {
+			if err := verifySignature(path, data, publicKey); err != nil {
+				return nil, err
+			}
+		}
 		if err := json.Unmarshal(data, cfg); err != nil {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
+		if err := resolveSecretRefs(cfg); err != nil {
+			return nil, fmt.Errorf("failed to resolve config secrets: %w", err)
+		}
+	}
+
+	// Environment overrides the file, and options override both. A signed
+	// config is pinned, so the environment must not change it behind the
+	// signature's back.
+	if publicKey != nil {
+		if names := envSet(); len(names) > 0 {
+			return nil, fmt.Errorf("%w: %s would override the signed config", ErrConfigSignature, strings.Join(names, ", "))
+		}
+	}
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
 	}
 
 	// Apply command-line options
@@ -235,6 +480,9 @@ func Load(path string, opts *Options) (*Config, error) {
 		if opts.Mode != "" {
 			cfg.Mode = opts.Mode
 		}
+		if opts.NetworkID != 0 {
+			cfg.Network.NetworkID = opts.NetworkID
+		}
 		if opts.DataDir != "" {
 			cfg.DataDir = opts.DataDir
 		}
@@ -244,17 +492,159 @@ func Load(path string, opts *Options) (*Config, error) {
 		if opts.P2PAddr != "" {
 			cfg.Network.P2PAddr = opts.P2PAddr
 		}
-		cfg.Warp.Enabled = opts.WarpEnable
-		cfg.Crypto.GPUEnabled = opts.GPUEnable
+		if opts.WarpEnable != nil {
+			cfg.Warp.Enabled = *opts.WarpEnable
+		}
+		if opts.GPUEnable != nil {
+			cfg.Crypto.GPUEnabled = *opts.GPUEnable
+		}
+		if opts.SignatureScheme != "" {
+			cfg.Crypto.SignatureScheme = opts.SignatureScheme
+		}
+		if opts.KEMScheme != "" {
+			cfg.Crypto.KEMScheme = opts.KEMScheme
+		}
+		if opts.SymmetricCipher != "" {
+			cfg.Crypto.SymmetricCipher = opts.SymmetricCipher
+		}
 	}
 
 	// Expand paths
 	cfg.DataDir = expandPath(cfg.DataDir)
-	cfg.Pars.Storage.DataDir = filepath.Join(cfg.DataDir, "storage")
+	cfg.Pars.Storage.PrimaryDir = expandPath(cfg.Pars.Storage.PrimaryDir)
+	// One storage bucket per network so a reused data dir never mixes
+	// mainnet and testnet messages
+	cfg.Pars.Storage.DataDir = filepath.Join(cfg.DataDir, "storage", strconv.FormatUint(uint64(cfg.Network.NetworkID), 10))
+	if cfg.Pars.Journal.Path == "" {
+		cfg.Pars.Journal.Path = filepath.Join(cfg.DataDir, "journal", "messages.log")
+	}
+	if cfg.Pars.IdentityFile == "" {
+		cfg.Pars.IdentityFile = filepath.Join(cfg.DataDir, "identity.json")
+	}
+	if cfg.Pars.TTL == (TTLBounds{}) {
+		cfg.Pars.TTL = DefaultTTLBounds(cfg.Network.NetworkID)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
+// Validate checks the configuration for unsupported settings
+func (c *Config) Validate() error {
+	if !slices.Contains(Modes, string(c.Mode)) {
+		return fmt.Errorf("unknown mode %q (supported: %s)", c.Mode, strings.Join(Modes, ", "))
+	}
+	if c.Network.ChainID == 0 {
+		return errors.New("invalid network.chainId: must be non-zero")
+	}
+	if c.Network.NetworkID == 0 {
+		return errors.New("invalid network.networkId: must be non-zero")
+	}
+	if bt := c.Consensus.BlockTimeMs; bt < MinBlockTimeMs || bt > MaxBlockTimeMs {
+		return fmt.Errorf("invalid consensus.blockTimeMs %d: must be within [%d, %d]", bt, MinBlockTimeMs, MaxBlockTimeMs)
+	}
+	if !slices.Contains(Compressions, c.Pars.Compression) {
+		return fmt.Errorf("unknown compression %q (supported: %s)", c.Pars.Compression, strings.Join(Compressions, ", "))
+	}
+	if !slices.Contains(Serializations, c.Pars.Serialization) {
+		return fmt.Errorf("unknown serialization %q (supported: %s)", c.Pars.Serialization, strings.Join(Serializations, ", "))
+	}
+	if mb := c.Pars.Padding.MaxBucket; mb > 0 && mb&(mb-1) != 0 {
+		return fmt.Errorf("invalid pars.padding.maxBucket %d: must be a power of two", mb)
+	}
+	if c.Pars.MaxWorkers < 0 {
+		return fmt.Errorf("invalid pars.maxWorkers %d: must not be negative", c.Pars.MaxWorkers)
+	}
+	dl := c.Pars.DeadLetter
+	if dl.MaxAttempts < 1 {
+		return fmt.Errorf("invalid pars.deadLetter.maxAttempts %d: must be at least 1", dl.MaxAttempts)
+	}
+	if dl.MaxEntries < 0 {
+		return fmt.Errorf("invalid pars.deadLetter.maxEntries %d: must not be negative", dl.MaxEntries)
+	}
+	if dl.Retention < 0 {
+		return fmt.Errorf("invalid pars.deadLetter.retention %d: must not be negative", dl.Retention)
+	}
+	if dl.InitialBackoffMs < 0 {
+		return fmt.Errorf("invalid pars.deadLetter.initialBackoffMs %d: must not be negative", dl.InitialBackoffMs)
+	}
+	if dl.MaxBackoffMs < dl.InitialBackoffMs {
+		return fmt.Errorf("invalid pars.deadLetter.maxBackoffMs %d: must be at least pars.deadLetter.initialBackoffMs %d",
+			dl.MaxBackoffMs, dl.InitialBackoffMs)
+	}
+	if c.Pars.Storage.GCInterval < 0 {
+		return fmt.Errorf("invalid pars.storage.gcInterval %d: must not be negative", c.Pars.Storage.GCInterval)
+	}
+	if c.Pars.MaxClockSkew < 0 {
+		return fmt.Errorf("invalid pars.maxClockSkew %d: must not be negative", c.Pars.MaxClockSkew)
+	}
+	if ttl := c.Pars.TTL; ttl.Min < 0 {
+		return fmt.Errorf("invalid pars.ttl.min %d: must not be negative", ttl.Min)
+	}
+	if ttl := c.Pars.TTL; ttl.Max > 0 && ttl.Max < ttl.Min {
+		return fmt.Errorf("invalid pars.ttl.max %d: must be 0 or at least pars.ttl.min %d", ttl.Max, ttl.Min)
+	}
+	if !slices.Contains(KEMSchemes, c.Crypto.KEMScheme) {
+		return fmt.Errorf("unknown KEM scheme %q (supported: %s)", c.Crypto.KEMScheme, strings.Join(KEMSchemes, ", "))
+	}
+	if !slices.Contains(SignatureSchemes, c.Crypto.SignatureScheme) {
+		return fmt.Errorf("unknown signature scheme %q (supported: %s)", c.Crypto.SignatureScheme, strings.Join(SignatureSchemes, ", "))
+	}
+	if !slices.Contains(SymmetricCiphers, c.Crypto.SymmetricCipher) {
+		return fmt.Errorf("unknown symmetric cipher %q (supported: %s)", c.Crypto.SymmetricCipher, strings.Join(SymmetricCiphers, ", "))
+	}
+	if !slices.Contains(SessionIDEncodings, c.Pars.Session.IDEncoding) {
+		return fmt.Errorf("unknown session ID encoding %q (supported: %s)", c.Pars.Session.IDEncoding, strings.Join(SessionIDEncodings, ", "))
+	}
+	if !slices.Contains(StorageRoles, c.Pars.Storage.Role) {
+		return fmt.Errorf("unknown storage role %q (supported: %s)", c.Pars.Storage.Role, strings.Join(StorageRoles, ", "))
+	}
+	if err := c.EVM.Precompiles.validate(); err != nil {
+		return err
+	}
+	if !slices.Contains(PrecompileChecks, c.EVM.PrecompileCheck) {
+		return fmt.Errorf("unknown precompile check %q (supported: %s)", c.EVM.PrecompileCheck, strings.Join(PrecompileChecks, ", "))
+	}
+	return c.validateDependencies()
+}
+
+// ErrConflictingConfig reports options set in a combination that cannot work
+var ErrConflictingConfig = errors.New("conflicting config")
+
+// validateDependencies checks options that only make sense together,
+// reporting every violation with the fields involved
+func (c *Config) validateDependencies() error {
+	var errs []error
+	if c.Pars.Onion.Enabled && c.Pars.Onion.HopCount < 1 {
+		errs = append(errs, fmt.Errorf("%w: pars.onion.enabled requires pars.onion.hopCount >= 1, got %d",
+			ErrConflictingConfig, c.Pars.Onion.HopCount))
+	}
+	if c.Pars.Storage.Enabled && c.Pars.Storage.MaxSize == 0 {
+		errs = append(errs, fmt.Errorf("%w: pars.storage.enabled requires pars.storage.maxSize > 0",
+			ErrConflictingConfig))
+	}
+	if c.Pars.Padding.Enabled && c.Pars.Padding.MaxBucket <= 0 {
+		errs = append(errs, fmt.Errorf("%w: pars.padding.enabled requires pars.padding.maxBucket > 0, got %d",
+			ErrConflictingConfig, c.Pars.Padding.MaxBucket))
+	}
+	if c.Pars.Storage.Role == "follower" && c.Pars.Storage.PrimaryDir == "" {
+		errs = append(errs, fmt.Errorf("%w: pars.storage.role follower requires pars.storage.primaryDir",
+			ErrConflictingConfig))
+	}
+	if c.Pars.Storage.PrimaryDir != "" && c.Pars.Storage.ReplicateInterval <= 0 {
+		errs = append(errs, fmt.Errorf("%w: pars.storage.primaryDir requires pars.storage.replicateInterval > 0, got %d",
+			ErrConflictingConfig, c.Pars.Storage.ReplicateInterval))
+	}
+	if c.Warp.Enabled && c.Warp.LuxEndpoint == "" {
+		errs = append(errs, fmt.Errorf("%w: warp.enabled requires warp.luxEndpoint",
+			ErrConflictingConfig))
+	}
+	return errors.Join(errs...)
+}
+
 func expandPath(path string) string {
 	if len(path) > 0 && path[0] == '~' {
 		home, _ := os.UserHomeDir()
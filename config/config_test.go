@@ -1,6 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -31,25 +36,29 @@ func TestModeValidation(t *testing.T) {
 	}{
 		{ModeL1, true},
 		{ModeL2, true},
-		{Mode("invalid"), false},
+		{Mode("L3"), false},
+		{Mode(""), false},
 	}
 
 	for _, tt := range tests {
-		valid := tt.mode == ModeL1 || tt.mode == ModeL2
-		if valid != tt.valid {
-			t.Errorf("mode %s: expected valid=%v, got %v", tt.mode, tt.valid, valid)
+		cfg := Default()
+		cfg.Mode = tt.mode
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("mode %s: expected valid=%v, got %v", tt.mode, tt.valid, err)
 		}
 	}
 }
 
 func TestLoadWithOptions(t *testing.T) {
+	disabled := false
 	opts := &Options{
 		Mode:       ModeL2,
+		NetworkID:  7072,
 		DataDir:    "/tmp/test-pars",
 		RPCAddr:    "127.0.0.1:8080",
 		P2PAddr:    "0.0.0.0:8081",
-		WarpEnable: false,
-		GPUEnable:  false,
+		WarpEnable: &disabled,
+		GPUEnable:  &disabled,
 	}
 
 	cfg, err := Load("", opts)
@@ -65,6 +74,14 @@ func TestLoadWithOptions(t *testing.T) {
 		t.Errorf("expected datadir /tmp/test-pars, got %s", cfg.DataDir)
 	}
 
+	// Paths and bounds derive from the overridden network and data dir
+	if want := "/tmp/test-pars/storage/7072"; cfg.Pars.Storage.DataDir != want {
+		t.Errorf("expected storage dir %s, got %s", want, cfg.Pars.Storage.DataDir)
+	}
+	if want := DefaultTTLBounds(7072); cfg.Pars.TTL != want {
+		t.Errorf("expected devnet TTL bounds %+v, got %+v", want, cfg.Pars.TTL)
+	}
+
 	if cfg.Warp.Enabled != false {
 		t.Error("expected warp disabled")
 	}
@@ -73,3 +90,301 @@ func TestLoadWithOptions(t *testing.T) {
 		t.Error("expected GPU disabled")
 	}
 }
+
+func TestLoadCryptoOverrides(t *testing.T) {
+	cfg, err := Load("", &Options{
+		SignatureScheme: "ML-DSA-87",
+		KEMScheme:       "ML-KEM-1024",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Crypto.SignatureScheme != "ML-DSA-87" {
+		t.Errorf("expected ML-DSA-87, got %s", cfg.Crypto.SignatureScheme)
+	}
+	if cfg.Crypto.KEMScheme != "ML-KEM-1024" {
+		t.Errorf("expected ML-KEM-1024, got %s", cfg.Crypto.KEMScheme)
+	}
+	if cfg.Crypto.SymmetricCipher != "XChaCha20-Poly1305" {
+		t.Errorf("expected default cipher kept, got %s", cfg.Crypto.SymmetricCipher)
+	}
+	if !cfg.Warp.Enabled {
+		t.Error("expected unset WarpEnable to keep warp enabled")
+	}
+
+	for _, opts := range []*Options{
+		{SignatureScheme: "Dilithium3"},
+		{KEMScheme: "Kyber768"},
+		{SymmetricCipher: "AES-128-ECB"},
+	} {
+		if _, err := Load("", opts); err == nil {
+			t.Errorf("expected error for %+v", *opts)
+		}
+	}
+}
+
+func TestValidateKEMScheme(t *testing.T) {
+	tests := []struct {
+		scheme string
+		valid  bool
+	}{
+		{"ML-KEM-512", true},
+		{"ML-KEM-768", true},
+		{"ML-KEM-1024", true},
+		{"Kyber768", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.Crypto.KEMScheme = tt.scheme
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid=%v, got %v", tt.scheme, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateCompression(t *testing.T) {
+	tests := []struct {
+		compression string
+		valid       bool
+	}{
+		{"none", true},
+		{"gzip", true},
+		{"zstd", true},
+		{"lz4", false},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.Pars.Compression = tt.compression
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid=%v, got %v", tt.compression, tt.valid, err)
+		}
+	}
+}
+
+func TestValidatePaddingBucket(t *testing.T) {
+	for _, tt := range []struct {
+		bucket int
+		valid  bool
+	}{
+		{64 * 1024, true},
+		{256, true},
+		{1, true},
+		{1000, false},
+		{3 * 1024, false},
+	} {
+		cfg := Default()
+		cfg.Pars.Padding.MaxBucket = tt.bucket
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("maxBucket %d: expected valid=%v, got %v", tt.bucket, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateFieldNames(t *testing.T) {
+	tests := []struct {
+		field  string
+		modify func(*Config)
+	}{
+		{"pars.maxWorkers", func(c *Config) { c.Pars.MaxWorkers = -1 }},
+		{"pars.deadLetter.maxAttempts", func(c *Config) { c.Pars.DeadLetter.MaxAttempts = 0 }},
+		{"pars.deadLetter.maxEntries", func(c *Config) { c.Pars.DeadLetter.MaxEntries = -1 }},
+		{"pars.deadLetter.retention", func(c *Config) { c.Pars.DeadLetter.Retention = -1 }},
+		{"pars.deadLetter.initialBackoffMs", func(c *Config) { c.Pars.DeadLetter.InitialBackoffMs = -1 }},
+		{"pars.deadLetter.maxBackoffMs", func(c *Config) { c.Pars.DeadLetter.MaxBackoffMs = c.Pars.DeadLetter.InitialBackoffMs - 1 }},
+		{"pars.storage.gcInterval", func(c *Config) { c.Pars.Storage.GCInterval = -1 }},
+		{"pars.maxClockSkew", func(c *Config) { c.Pars.MaxClockSkew = -1 }},
+		{"pars.ttl.min", func(c *Config) { c.Pars.TTL.Min = -1 }},
+		{"pars.ttl.max", func(c *Config) { c.Pars.TTL = TTLBounds{Min: 60, Max: 30} }},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		tt.modify(cfg)
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "invalid "+tt.field+" ") {
+			t.Errorf("%s: expected an error naming the field, got %v", tt.field, err)
+		}
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		fields []string
+	}{
+		{"defaults", func(*Config) {}, nil},
+		{"onion without hops", func(c *Config) {
+			c.Pars.Onion.HopCount = 0
+		}, []string{"pars.onion.enabled", "pars.onion.hopCount"}},
+		{"onion disabled without hops", func(c *Config) {
+			c.Pars.Onion.Enabled = false
+			c.Pars.Onion.HopCount = 0
+		}, nil},
+		{"gpu with any signature scheme", func(c *Config) {
+			c.Crypto.SignatureScheme = "ML-DSA-87"
+		}, nil},
+		{"storage without quota", func(c *Config) {
+			c.Pars.Storage.MaxSize = 0
+		}, []string{"pars.storage.enabled", "pars.storage.maxSize"}},
+		{"storage disabled without quota", func(c *Config) {
+			c.Pars.Storage.Enabled = false
+			c.Pars.Storage.MaxSize = 0
+		}, nil},
+		{"follower without primary", func(c *Config) {
+			c.Pars.Storage.Role = "follower"
+		}, []string{"pars.storage.role", "pars.storage.primaryDir"}},
+		{"follower of a primary", func(c *Config) {
+			c.Pars.Storage.Role = "follower"
+			c.Pars.Storage.PrimaryDir = "/mnt/primary/storage/7070"
+		}, nil},
+		{"several violations", func(c *Config) {
+			c.Pars.Padding.MaxBucket = 0
+			c.Warp.LuxEndpoint = ""
+		}, []string{"pars.padding.maxBucket", "warp.luxEndpoint"}},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		tt.modify(cfg)
+		err := cfg.Validate()
+		if tt.fields == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrConflictingConfig) {
+			t.Errorf("%s: expected ErrConflictingConfig, got %v", tt.name, err)
+			continue
+		}
+		for _, field := range tt.fields {
+			if !strings.Contains(err.Error(), field) {
+				t.Errorf("%s: expected %s in %q", tt.name, field, err)
+			}
+		}
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	cfg := Default()
+	if cfg.FeatureEnabled(FeatureGroupSessions) {
+		t.Error("expected experimental features off by default")
+	}
+	if err := cfg.Features.Require(FeatureGroupSessions); !errors.Is(err, ErrFeatureDisabled) {
+		t.Errorf("expected ErrFeatureDisabled, got %v", err)
+	}
+
+	cfg.Features = Features{FeatureGroupSessions: true, "warp-drive": true}
+	if !cfg.FeatureEnabled(FeatureGroupSessions) {
+		t.Error("expected group sessions enabled")
+	}
+	if unknown := cfg.Features.Unknown(); len(unknown) != 1 || unknown[0] != "warp-drive" {
+		t.Errorf("expected [warp-drive] unknown, got %v", unknown)
+	}
+}
+
+func TestStorageDirPerNetwork(t *testing.T) {
+	dataDir := t.TempDir()
+	dirs := make(map[string]uint32)
+	for _, network := range []uint32{7070, 7071} {
+		path := filepath.Join(t.TempDir(), "pars.json")
+		data := fmt.Sprintf(`{"dataDir": %q, "network": {"networkId": %d}}`, dataDir, network)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := filepath.Join(dataDir, "storage", fmt.Sprint(network))
+		if cfg.Pars.Storage.DataDir != want {
+			t.Errorf("network %d: expected storage dir %s, got %s", network, want, cfg.Pars.Storage.DataDir)
+		}
+		if other, ok := dirs[cfg.Pars.Storage.DataDir]; ok {
+			t.Errorf("networks %d and %d share storage dir %s", other, network, cfg.Pars.Storage.DataDir)
+		}
+		dirs[cfg.Pars.Storage.DataDir] = network
+	}
+}
+
+func TestLoadTTLBoundsForNetwork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pars.json")
+	if err := os.WriteFile(path, []byte(`{"network": {"networkId": 7072}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Pars.TTL != DefaultTTLBounds(7072) {
+		t.Errorf("expected devnet TTL bounds, got %+v", cfg.Pars.TTL)
+	}
+
+	cfg.Pars.TTL = TTLBounds{Min: 100, Max: 10}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for max below min")
+	}
+}
+
+func TestValidatePrecompiles(t *testing.T) {
+	tests := []struct {
+		addr  string
+		valid bool
+	}{
+		{"0x0800", true},
+		{"0X0B00", true},
+		{"", true},
+		{"0x0000000000000000000000000000000000000800", true},
+		{"0x", false},
+		{"0xZZ", false},
+		{"0800", false},
+		{"0x00000000000000000000000000000000000000800", false},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.EVM.Precompiles.FHE = tt.addr
+		err := cfg.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid=%v, got %v", tt.addr, tt.valid, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "evm.precompiles.fhe") {
+			t.Errorf("%q: expected the field named in %q", tt.addr, err)
+		}
+	}
+}
+
+func TestValidateNetworkAndConsensus(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		field  string
+	}{
+		{"zero chain ID", func(c *Config) { c.Network.ChainID = 0 }, "network.chainId"},
+		{"zero network ID", func(c *Config) { c.Network.NetworkID = 0 }, "network.networkId"},
+		{"zero block time", func(c *Config) { c.Consensus.BlockTimeMs = 0 }, "consensus.blockTimeMs"},
+		{"block time too short", func(c *Config) { c.Consensus.BlockTimeMs = MinBlockTimeMs - 1 }, "consensus.blockTimeMs"},
+		{"block time too long", func(c *Config) { c.Consensus.BlockTimeMs = MaxBlockTimeMs + 1 }, "consensus.blockTimeMs"},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		tt.modify(cfg)
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.field) {
+			t.Errorf("%s: expected error naming %s, got %v", tt.name, tt.field, err)
+		}
+	}
+
+	cfg := Default()
+	cfg.Consensus.BlockTimeMs = MaxBlockTimeMs
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("block time at maximum: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// EnvPrefix starts every environment variable override
+const EnvPrefix = "PARS_"
+
+var ErrInvalidEnv = errors.New("invalid environment override")
+
+// envOverrides maps environment variables to the config fields they set.
+// Names are EnvPrefix plus the upper-cased JSON path with dots as
+// underscores, e.g. network.rpcAddr is PARS_NETWORK_RPCADDR. Load applies
+// them over the file and defaults, and Options over them.
+var envOverrides = []struct {
+	name  string
+	field func(*Config) any // Pointer to the field set
+}{
+	{"PARS_MODE", func(c *Config) any { return &c.Mode }},
+	{"PARS_DATADIR", func(c *Config) any { return &c.DataDir }},
+
+	{"PARS_NETWORK_RPCADDR", func(c *Config) any { return &c.Network.RPCAddr }},
+	{"PARS_NETWORK_P2PADDR", func(c *Config) any { return &c.Network.P2PAddr }},
+	{"PARS_NETWORK_CHAINID", func(c *Config) any { return &c.Network.ChainID }},
+	{"PARS_NETWORK_NETWORKID", func(c *Config) any { return &c.Network.NetworkID }},
+
+	{"PARS_EVM_ENABLED", func(c *Config) any { return &c.EVM.Enabled }},
+	{"PARS_EVM_CHAINID", func(c *Config) any { return &c.EVM.ChainID }},
+	{"PARS_EVM_GASLIMIT", func(c *Config) any { return &c.EVM.GasLimit }},
+	{"PARS_EVM_RPCENDPOINT", func(c *Config) any { return &c.EVM.RPCEndpoint }},
+
+	{"PARS_CRYPTO_GPUENABLED", func(c *Config) any { return &c.Crypto.GPUEnabled }},
+	{"PARS_CRYPTO_SIGNATURESCHEME", func(c *Config) any { return &c.Crypto.SignatureScheme }},
+	{"PARS_CRYPTO_KEMSCHEME", func(c *Config) any { return &c.Crypto.KEMScheme }},
+	{"PARS_CRYPTO_SYMMETRICCIPHER", func(c *Config) any { return &c.Crypto.SymmetricCipher }},
+
+	{"PARS_WARP_ENABLED", func(c *Config) any { return &c.Warp.Enabled }},
+	{"PARS_WARP_LUXENDPOINT", func(c *Config) any { return &c.Warp.LuxEndpoint }},
+
+	{"PARS_CONSENSUS_BLOCKTIMEMS", func(c *Config) any { return &c.Consensus.BlockTimeMs }},
+
+	{"PARS_PARS_ENABLED", func(c *Config) any { return &c.Pars.Enabled }},
+	{"PARS_PARS_STORAGE_ENABLED", func(c *Config) any { return &c.Pars.Storage.Enabled }},
+	{"PARS_PARS_STORAGE_MAXSIZE", func(c *Config) any { return &c.Pars.Storage.MaxSize }},
+	{"PARS_PARS_STORAGE_ROLE", func(c *Config) any { return &c.Pars.Storage.Role }},
+	{"PARS_PARS_STORAGE_PRIMARYDIR", func(c *Config) any { return &c.Pars.Storage.PrimaryDir }},
+	{"PARS_PARS_ONION_ENABLED", func(c *Config) any { return &c.Pars.Onion.Enabled }},
+	{"PARS_PARS_ONION_HOPCOUNT", func(c *Config) any { return &c.Pars.Onion.HopCount }},
+}
+
+// envSet returns the names of the envOverrides present in the environment
+func envSet() []string {
+	var names []string
+	for _, env := range envOverrides {
+		if _, ok := os.LookupEnv(env.name); ok {
+			names = append(names, env.name)
+		}
+	}
+	return names
+}
+
+// applyEnv sets cfg fields from any envOverrides present in the
+// environment. A malformed value is an error, never silently ignored.
+func applyEnv(cfg *Config) error {
+	for _, env := range envOverrides {
+		value, ok := os.LookupEnv(env.name)
+		if !ok {
+			continue
+		}
+		if err := setField(env.field(cfg), value); err != nil {
+			return fmt.Errorf("%w: %s=%q: %v", ErrInvalidEnv, env.name, value, err)
+		}
+	}
+	return nil
+}
+
+// setField parses value into the field p points to
+func setField(p any, value string) error {
+	switch p := p.(type) {
+	case *string:
+		*p = value
+	case *Mode:
+		*p = Mode(value)
+	case *bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("not a boolean")
+		}
+		*p = v
+	case *int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		*p = v
+	case *uint32:
+		v, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return errors.New("not an unsigned 32-bit integer")
+		}
+		*p = uint32(v)
+	case *uint64:
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return errors.New("not an unsigned integer")
+		}
+		*p = v
+	default:
+		return fmt.Errorf("unsupported field type %T", p)
+	}
+	return nil
+}
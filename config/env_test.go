@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadEnvPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	file := `{"network": {"rpcAddr": "10.0.0.1:9650"}, "evm": {"chainId": 1111}, "crypto": {"kemScheme": "ML-KEM-512"}}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PARS_NETWORK_RPCADDR", "10.0.0.2:9650")
+	t.Setenv("PARS_EVM_CHAINID", "2222")
+	t.Setenv("PARS_CRYPTO_GPUENABLED", "false")
+	t.Setenv("PARS_CRYPTO_KEMSCHEME", "ML-KEM-1024")
+
+	cfg, err := Load(path, &Options{KEMScheme: "ML-KEM-768"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Network.RPCAddr != "10.0.0.2:9650" {
+		t.Errorf("expected env rpcAddr over file, got %s", cfg.Network.RPCAddr)
+	}
+	if cfg.EVM.ChainID != 2222 {
+		t.Errorf("expected env chainId over file, got %d", cfg.EVM.ChainID)
+	}
+	if cfg.Crypto.GPUEnabled {
+		t.Error("expected env to disable GPU over default")
+	}
+	if cfg.Crypto.KEMScheme != "ML-KEM-768" {
+		t.Errorf("expected option KEM scheme over env, got %s", cfg.Crypto.KEMScheme)
+	}
+}
+
+func TestLoadEnvMalformed(t *testing.T) {
+	tests := []struct {
+		name, value string
+	}{
+		{"PARS_CRYPTO_GPUENABLED", "maybe"},
+		{"PARS_EVM_CHAINID", "-1"},
+		{"PARS_NETWORK_NETWORKID", "5000000000"},
+		{"PARS_PARS_ONION_HOPCOUNT", "three"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			_, err := Load("", nil)
+			if !errors.Is(err, ErrInvalidEnv) || !strings.Contains(err.Error(), tt.name) {
+				t.Errorf("expected ErrInvalidEnv naming %s, got %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestEnvOverridesResolve(t *testing.T) {
+	// Every table entry must point at a field setField can parse
+	cfg := Default()
+	for _, env := range envOverrides {
+		if !strings.HasPrefix(env.name, EnvPrefix) {
+			t.Errorf("%s: missing %s prefix", env.name, EnvPrefix)
+		}
+		if err := setField(env.field(cfg), ""); err != nil && strings.Contains(err.Error(), "unsupported") {
+			t.Errorf("%s: %v", env.name, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Experimental features that ship disabled unless turned on in config
+const (
+	FeatureFHEPrecompile   = "fhe-precompile"
+	FeatureLibp2pTransport = "libp2p-transport"
+	FeatureGroupSessions   = "group-sessions"
+)
+
+var ErrFeatureDisabled = errors.New("feature disabled")
+
+// knownFeatures lists every flag configs may set
+var knownFeatures = map[string]bool{
+	FeatureFHEPrecompile:   true,
+	FeatureLibp2pTransport: true,
+	FeatureGroupSessions:   true,
+}
+
+// Features maps feature flag names to their enabled state
+type Features map[string]bool
+
+// Enabled reports whether the named feature is turned on
+func (f Features) Enabled(name string) bool {
+	return f[name]
+}
+
+// Require returns ErrFeatureDisabled if the named feature is off
+func (f Features) Require(name string) error {
+	if !f.Enabled(name) {
+		return fmt.Errorf("%w: %s", ErrFeatureDisabled, name)
+	}
+	return nil
+}
+
+// Unknown returns configured flags the node does not recognize, sorted
+func (f Features) Unknown() []string {
+	var unknown []string
+	for name := range f {
+		if !knownFeatures[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// FeatureEnabled reports whether the named experimental feature is on
+func (c *Config) FeatureEnabled(name string) bool {
+	return c.Features.Enabled(name)
+}
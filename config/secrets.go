@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// secretFilePrefix marks a config string whose value is read from a file
+const secretFilePrefix = "file:"
+
+// resolveSecretRefs replaces every "file:/path" string in cfg with the
+// contents of the referenced file, without its trailing newline
+func resolveSecretRefs(cfg *Config) error {
+	return resolveValue(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func resolveValue(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.String:
+		ref, ok := strings.CutPrefix(v.String(), secretFilePrefix)
+		if !ok {
+			return nil
+		}
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return fmt.Errorf("%s: failed to read secret file: %w", path, err)
+		}
+		v.SetString(strings.TrimRight(string(data), "\r\n"))
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := resolveValue(v.Field(i), joinPath(path, jsonName(field))); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonName returns the config file key of field
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/luxfi/crypto/mldsa"
+)
+
+// SignatureSuffix is appended to the config path to find its detached
+// ML-DSA-65 signature
+const SignatureSuffix = ".sig"
+
+var ErrConfigSignature = errors.New("config signature verification failed")
+
+// verifySignature checks the detached signature at path+SignatureSuffix
+// over the raw config file contents against the ML-DSA-65 public key
+func verifySignature(path string, data, publicKey []byte) error {
+	pub, err := mldsa.PublicKeyFromBytes(publicKey, mldsa.MLDSA65)
+	if err != nil {
+		return fmt.Errorf("invalid config verification key: %w", err)
+	}
+
+	sig, err := os.ReadFile(path + SignatureSuffix)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigSignature, err)
+	}
+	if !pub.VerifySignature(data, sig) {
+		return fmt.Errorf("%w: %s does not match %s", ErrConfigSignature, path+SignatureSuffix, path)
+	}
+	return nil
+}
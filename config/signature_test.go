@@ -0,0 +1,61 @@
+package config
+
+import (
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/crypto/mldsa"
+)
+
+func TestLoadVerified(t *testing.T) {
+	key, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA65)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pars.json")
+	data := []byte(`{"pars": {"maxWorkers": 8}}`)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := key.Sign(rand.Reader, data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+SignatureSuffix, sig, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadVerified(path, key.PublicKey.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Pars.MaxWorkers != 8 {
+		t.Errorf("expected signed config to load, got maxWorkers %d", cfg.Pars.MaxWorkers)
+	}
+
+	// The environment cannot override a signed config
+	t.Setenv("PARS_NETWORK_RPCADDR", "10.0.0.2:9650")
+	if _, err := LoadVerified(path, key.PublicKey.Bytes(), nil); !errors.Is(err, ErrConfigSignature) {
+		t.Errorf("expected ErrConfigSignature with an env override set, got %v", err)
+	}
+	os.Unsetenv("PARS_NETWORK_RPCADDR")
+
+	// Tamper with the config but keep the old signature
+	if err := os.WriteFile(path, []byte(`{"pars": {"maxWorkers": 9}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadVerified(path, key.PublicKey.Bytes(), nil); !errors.Is(err, ErrConfigSignature) {
+		t.Errorf("expected ErrConfigSignature for modified config, got %v", err)
+	}
+
+	if err := os.Remove(path + SignatureSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadVerified(path, key.PublicKey.Bytes(), nil); !errors.Is(err, ErrConfigSignature) {
+		t.Errorf("expected ErrConfigSignature for missing signature, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package genesis embeds the Pars network genesis
+package genesis
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+//go:embed network-genesis.json cchain-genesis.json
+var files embed.FS
+
+var ErrInvalidGenesis = errors.New("invalid genesis")
+
+// Build returns the luxd network genesis for networkID, with the PARS
+// allocations and stakers and the C-Chain genesis for chainID embedded
+func Build(networkID uint32, chainID uint64) ([]byte, error) {
+	network, err := readObject("network-genesis.json")
+	if err != nil {
+		return nil, err
+	}
+	cchain, err := readObject("cchain-genesis.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var chainConfig map[string]json.RawMessage
+	if err := json.Unmarshal(cchain["config"], &chainConfig); err != nil {
+		return nil, fmt.Errorf("%w: C-Chain config: %v", ErrInvalidGenesis, err)
+	}
+	chainConfig["chainId"] = mustMarshal(chainID)
+	cchain["config"] = mustMarshal(chainConfig)
+
+	// luxd takes the C-Chain genesis as a JSON string
+	network["networkID"] = mustMarshal(networkID)
+	network["cChainGenesis"] = mustMarshal(string(mustMarshal(cchain)))
+
+	data, err := json.MarshalIndent(network, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(data, networkID, chainID); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Validate checks that data parses as a network genesis for networkID
+// whose C-Chain genesis has chainID and that it allocates PARS
+func Validate(data []byte, networkID uint32, chainID uint64) error {
+	var g struct {
+		NetworkID     uint32            `json:"networkID"`
+		Allocations   []json.RawMessage `json:"allocations"`
+		CChainGenesis string            `json:"cChainGenesis"`
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGenesis, err)
+	}
+	if g.NetworkID != networkID {
+		return fmt.Errorf("%w: network ID %d, expected %d", ErrInvalidGenesis, g.NetworkID, networkID)
+	}
+	if len(g.Allocations) == 0 {
+		return fmt.Errorf("%w: no allocations", ErrInvalidGenesis)
+	}
+
+	var c struct {
+		Config struct {
+			ChainID uint64 `json:"chainId"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(g.CChainGenesis), &c); err != nil {
+		return fmt.Errorf("%w: C-Chain genesis: %v", ErrInvalidGenesis, err)
+	}
+	if c.Config.ChainID != chainID {
+		return fmt.Errorf("%w: C-Chain ID %d, expected %d", ErrInvalidGenesis, c.Config.ChainID, chainID)
+	}
+	return nil
+}
+
+// readObject parses an embedded file as a JSON object, keeping values
+// verbatim so large amounts are not rounded
+func readObject(name string) (map[string]json.RawMessage, error) {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidGenesis, name, err)
+	}
+	return obj, nil
+}
+
+// mustMarshal encodes values that cannot fail to marshal
+func mustMarshal(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
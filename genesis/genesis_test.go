@@ -0,0 +1,33 @@
+package genesis
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	data, err := Build(7071, 12345)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Validate(data, 7071, 12345); err != nil {
+		t.Errorf("built genesis invalid: %v", err)
+	}
+	if err := Validate(data, 7070, 12345); !errors.Is(err, ErrInvalidGenesis) {
+		t.Errorf("expected network ID mismatch, got %v", err)
+	}
+
+	// Allocations are carried over exactly
+	var g struct {
+		Allocations []struct {
+			InitialAmount json.Number `json:"initialAmount"`
+		} `json:"allocations"`
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Allocations) == 0 || g.Allocations[0].InitialAmount != "100000000000000000" {
+		t.Errorf("expected PARS allocations preserved, got %+v", g.Allocations)
+	}
+}
@@ -3,23 +3,26 @@ module github.com/parsdao/node
 go 1.25.5
 
 require (
+	github.com/fxamacker/cbor/v2 v2.9.4
+	github.com/klauspost/compress v1.18.0
+	github.com/luxfi/crypto v1.17.38
 	github.com/luxfi/ids v1.2.9
 	github.com/luxfi/log v1.4.1
 	github.com/luxfi/session v0.1.0
+	golang.org/x/crypto v0.47.0
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
 
 require (
 	github.com/cloudflare/circl v1.6.2 // indirect
 	github.com/gorilla/rpc v1.2.1 // indirect
-	github.com/luxfi/crypto v1.17.38 // indirect
 	github.com/luxfi/mock v0.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mr-tron/base58 v1.2.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	go.uber.org/mock v0.6.0 // indirect
-	golang.org/x/crypto v0.47.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
-	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 )
 
 replace github.com/luxfi/session => ../../lux/session
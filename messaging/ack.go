@@ -0,0 +1,155 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAckTimeout   = errors.New("message not acknowledged")
+	ErrUnknownAck   = errors.New("no message awaiting this acknowledgement")
+	ErrDuplicateAck = errors.New("message ID already awaiting an acknowledgement")
+)
+
+// AckHandle resolves when the recipient acknowledges a sent message
+type AckHandle struct {
+	id      string
+	done    chan struct{}
+	expires time.Time // When the message's TTL runs out; zero for never
+	m       *Messenger
+}
+
+// Wait blocks until the message is acknowledged, ctx is done or the
+// message expires. In the latter cases it stops waiting for the ack and
+// returns ErrAckTimeout.
+func (h *AckHandle) Wait(ctx context.Context) error {
+	select {
+	case <-h.done:
+		return nil
+	default:
+	}
+
+	var expired <-chan time.Time
+	if !h.expires.IsZero() {
+		timer := time.NewTimer(h.expires.Sub(h.m.now()))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-h.done:
+		return nil
+	case <-expired:
+		h.m.acks.remove(h)
+		return fmt.Errorf("%w: %s: message expired", ErrAckTimeout, h.id)
+	case <-ctx.Done():
+		if h.m != nil {
+			h.m.acks.remove(h)
+		}
+		return fmt.Errorf("%w: %s: %w", ErrAckTimeout, h.id, ctx.Err())
+	}
+}
+
+// pendingAcks holds the handles of sent messages awaiting an ack, by
+// message ID. Handles nobody waits on are dropped once their message
+// expires, as no ack can arrive after that.
+type pendingAcks struct {
+	mu      sync.Mutex
+	pending map[string]*AckHandle
+}
+
+// add registers h, pruning expired handles. It fails if a live handle
+// already awaits the same message ID.
+func (p *pendingAcks) add(h *AckHandle, now time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		p.pending = make(map[string]*AckHandle)
+	}
+	for id, pending := range p.pending {
+		if pending.expired(now) {
+			delete(p.pending, id)
+		}
+	}
+	if _, ok := p.pending[h.id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateAck, h.id)
+	}
+	p.pending[h.id] = h
+	return nil
+}
+
+// remove unregisters h, leaving any other handle for the same ID
+func (p *pendingAcks) remove(h *AckHandle) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending[h.id] == h {
+		delete(p.pending, h.id)
+	}
+}
+
+// take unregisters and returns the live handle for id, if any
+func (p *pendingAcks) take(id string, now time.Time) *AckHandle {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.pending[id]
+	delete(p.pending, id)
+	if h == nil || h.expired(now) {
+		return nil
+	}
+	return h
+}
+
+// expired reports whether h's message has expired at now
+func (h *AckHandle) expired(now time.Time) bool {
+	return !h.expires.IsZero() && !now.Before(h.expires)
+}
+
+// SendWithAck sends msg like Send. If msg.RequireAck is set, the returned
+// handle resolves once Ack is called for it, and waiting on it times out
+// when msg's TTL runs out; otherwise it is already resolved. Only one send
+// of a message ID may await an ack at a time.
+func (m *Messenger) SendWithAck(ctx context.Context, msg *Message) (*AckHandle, error) {
+	h := &AckHandle{id: msg.ID, done: make(chan struct{})}
+	if !msg.RequireAck {
+		close(h.done)
+		return h, m.Send(ctx, msg)
+	}
+
+	// Register first so an ack arriving during Send is not lost
+	h.m = m
+	now := m.now()
+	if msg.TTL > 0 {
+		h.expires = now.Add(time.Duration(msg.TTL) * time.Second)
+	}
+	if err := m.acks.add(h, now); err != nil {
+		return nil, err
+	}
+	if err := m.Send(ctx, msg); err != nil {
+		m.acks.remove(h)
+		return nil, err
+	}
+	return h, nil
+}
+
+// SendAndWait sends msg and, if it requires an ack, waits up to the
+// deadline of ctx for it
+func (m *Messenger) SendAndWait(ctx context.Context, msg *Message) error {
+	h, err := m.SendWithAck(ctx, msg)
+	if err != nil {
+		return err
+	}
+	return h.Wait(ctx)
+}
+
+// Ack records the recipient's acknowledgement of message id, resolving
+// its handle
+func (m *Messenger) Ack(id string) error {
+	h := m.acks.take(id, m.now())
+	if h == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownAck, id)
+	}
+	close(h.done)
+	return nil
+}
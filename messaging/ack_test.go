@@ -0,0 +1,126 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestSendAndWaitAck(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+
+	msg := testMessage()
+	msg.RequireAck = true
+	h, err := m.SendWithAck(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	go func() {
+		if err := m.Ack(msg.ID); err != nil {
+			t.Errorf("ack: unexpected error: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Wait(ctx); err != nil {
+		t.Errorf("expected ack, got %v", err)
+	}
+}
+
+func TestSendAndWaitTimeout(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+
+	msg := testMessage()
+	msg.RequireAck = true
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.SendAndWait(ctx, msg); !errors.Is(err, ErrAckTimeout) {
+		t.Errorf("expected ErrAckTimeout, got %v", err)
+	}
+	// A late ack finds nothing waiting
+	if err := m.Ack(msg.ID); !errors.Is(err, ErrUnknownAck) {
+		t.Errorf("expected ErrUnknownAck, got %v", err)
+	}
+}
+
+func TestSendWithoutAck(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h, err := m.SendWithAck(ctx, testMessage())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+	if err := h.Wait(ctx); err != nil {
+		t.Errorf("expected resolved handle, got %v", err)
+	}
+}
+
+func TestPendingAckExpiryAndDuplicates(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+	now := time.Unix(1700000000, 0)
+	m.now = func() time.Time { return now }
+
+	msg := testMessage()
+	msg.RequireAck = true
+	msg.TTL = 60
+	stale, err := m.SendWithAck(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := m.SendWithAck(context.Background(), msg); !errors.Is(err, ErrDuplicateAck) {
+		t.Errorf("expected ErrDuplicateAck, got %v", err)
+	}
+
+	// Never waited on, the handle is dropped once the message expires
+	now = now.Add(time.Minute)
+	if err := m.Ack(msg.ID); !errors.Is(err, ErrUnknownAck) {
+		t.Errorf("expected ErrUnknownAck after expiry, got %v", err)
+	}
+	if err := stale.Wait(context.Background()); !errors.Is(err, ErrAckTimeout) {
+		t.Errorf("expected ErrAckTimeout waiting on an expired message, got %v", err)
+	}
+
+	other := testMessage()
+	other.ID = "msg-2"
+	other.RequireAck = true
+	other.TTL = 60
+	if _, err := m.SendWithAck(context.Background(), other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	h, err := m.SendWithAck(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("expected the ID reusable once expired, got %v", err)
+	}
+	if n := len(m.acks.pending); n != 1 {
+		t.Errorf("expected expired handles pruned, %d pending", n)
+	}
+	if err := m.Ack(msg.ID); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if err := h.Wait(context.Background()); err != nil {
+		t.Errorf("expected ack, got %v", err)
+	}
+}
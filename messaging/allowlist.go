@@ -0,0 +1,80 @@
+package messaging
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrSenderNotAllowed = errors.New("sender not on session allowlist")
+
+// senderAllowlists holds the senders each restricted session accepts.
+// Session and sender IDs are stored in canonical hex so either encoding
+// matches.
+type senderAllowlists struct {
+	mu    sync.RWMutex
+	lists map[string]map[string]bool
+}
+
+// AllowSenders restricts sessionID to messages from senders, replacing
+// any earlier list. With no senders the session accepts anyone again.
+// Set it when creating the session.
+func (m *Messenger) AllowSenders(sessionID string, senders ...string) error {
+	session, err := canonicalSessionID(sessionID)
+	if err != nil {
+		return err
+	}
+	allowed := make(map[string]bool, len(senders))
+	for _, s := range senders {
+		sender, err := canonicalSessionID(s)
+		if err != nil {
+			return fmt.Errorf("allowlist sender: %w", err)
+		}
+		allowed[sender] = true
+	}
+
+	a := &m.allowlists
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(allowed) == 0 {
+		delete(a.lists, session)
+		return nil
+	}
+	if a.lists == nil {
+		a.lists = make(map[string]map[string]bool)
+	}
+	a.lists[session] = allowed
+	return nil
+}
+
+// checkSender rejects msg if sessionID has an allowlist without its
+// sender. Call it after Verify, so SenderID is authentic.
+func (m *Messenger) checkSender(sessionID string, msg *Message) error {
+	session, err := canonicalSessionID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	a := &m.allowlists
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	allowed, ok := a.lists[session]
+	if !ok {
+		return nil
+	}
+	sender, err := canonicalSessionID(msg.SenderID)
+	if err != nil || !allowed[sender] {
+		return fmt.Errorf("%w: %s from %s", ErrSenderNotAllowed, msg.ID, msg.SenderID)
+	}
+	return nil
+}
+
+// canonicalSessionID returns id in hex, whatever its encoding
+func canonicalSessionID(id string) (string, error) {
+	raw, err := ParseSessionID(id)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
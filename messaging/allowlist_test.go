@@ -0,0 +1,62 @@
+package messaging
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestSenderAllowlist(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stranger := PQPrefix + strings.Repeat("ef", 32)
+
+	allowed := testMessage()
+	blocked := testMessage()
+	blocked.SenderID = stranger
+
+	// Unrestricted sessions accept anyone
+	for _, msg := range []*Message{allowed, blocked} {
+		if err := m.checkSender(testRecipient, msg); err != nil {
+			t.Errorf("%s: unexpected error before allowlisting: %v", msg.SenderID, err)
+		}
+	}
+
+	// The allowlist matches whatever encoding the sender ID is given in
+	raw, err := ParseSessionID(testSender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bechSender, err := FormatSessionID(raw, SessionIDBech32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AllowSenders(testRecipient, bechSender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.checkSender(testRecipient, allowed); err != nil {
+		t.Errorf("expected allowlisted sender accepted, got %v", err)
+	}
+	if err := m.checkSender(testRecipient, blocked); !errors.Is(err, ErrSenderNotAllowed) {
+		t.Errorf("expected ErrSenderNotAllowed, got %v", err)
+	}
+	if err := m.checkSender(stranger, blocked); err != nil {
+		t.Errorf("expected other sessions unrestricted, got %v", err)
+	}
+
+	if err := m.AllowSenders(testRecipient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.checkSender(testRecipient, blocked); err != nil {
+		t.Errorf("expected clearing the allowlist to accept anyone, got %v", err)
+	}
+
+	if err := m.AllowSenders(testRecipient, "not-a-session"); !errors.Is(err, ErrInvalidSessionID) {
+		t.Errorf("expected ErrInvalidSessionID, got %v", err)
+	}
+}
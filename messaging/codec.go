@@ -0,0 +1,103 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Format bytes prefixed to every encoded message
+const (
+	formatJSON byte = 0x01
+	formatCBOR byte = 0x02
+)
+
+var ErrUnknownCodec = errors.New("unknown message serialization")
+
+// Codec serializes messages for storage or transmission
+type Codec interface {
+	// Name returns the format name used in config, e.g. "cbor"
+	Name() string
+
+	// Format returns the byte that identifies this codec on the wire
+	Format() byte
+
+	Marshal(msg *Message) ([]byte, error)
+	Unmarshal(data []byte, msg *Message) error
+}
+
+// codecs holds the supported serializations by name
+var codecs = map[string]Codec{
+	"json": jsonCodec{},
+	"cbor": newCBORCodec(),
+}
+
+// LookupCodec returns the codec registered under name
+func LookupCodec(name string) (Codec, error) {
+	c, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, name)
+	}
+	return c, nil
+}
+
+// codecForFormat returns the codec identified by a format byte
+func codecForFormat(format byte) (Codec, error) {
+	for _, c := range codecs {
+		if c.Format() == format {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: format byte 0x%02x", ErrUnknownCodec, format)
+}
+
+// jsonCodec is human readable, for debugging
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Format() byte {
+	return formatJSON
+}
+
+func (jsonCodec) Marshal(msg *Message) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+func (jsonCodec) Unmarshal(data []byte, msg *Message) error {
+	return json.Unmarshal(data, msg)
+}
+
+// cborCodec is the compact production encoding. Field names follow the
+// json tags.
+type cborCodec struct {
+	enc cbor.EncMode
+	dec cbor.DecMode
+}
+
+func newCBORCodec() cborCodec {
+	// Options are static and valid, so construction cannot fail
+	enc, _ := cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	dec, _ := cbor.DecOptions{DupMapKey: cbor.DupMapKeyEnforcedAPF}.DecMode()
+	return cborCodec{enc: enc, dec: dec}
+}
+
+func (cborCodec) Name() string {
+	return "cbor"
+}
+
+func (cborCodec) Format() byte {
+	return formatCBOR
+}
+
+func (c cborCodec) Marshal(msg *Message) ([]byte, error) {
+	return c.enc.Marshal(msg)
+}
+
+func (c cborCodec) Unmarshal(data []byte, msg *Message) error {
+	return c.dec.Unmarshal(data, msg)
+}
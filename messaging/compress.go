@@ -0,0 +1,128 @@
+package messaging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionNone sends payloads uncompressed
+	CompressionNone = "none"
+
+	// maxDecompressedSize bounds decompression of untrusted payloads
+	maxDecompressedSize = 16 * 1024 * 1024 // 16MB
+)
+
+var (
+	ErrUnknownCompressor = errors.New("unknown compression algorithm")
+	ErrTooLarge          = errors.New("decompressed payload too large")
+)
+
+// Compressor compresses message plaintext before encryption
+type Compressor interface {
+	// Name returns the algorithm name recorded in message headers
+	Name() string
+
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// compressors holds the supported algorithms by name
+var compressors = map[string]Compressor{
+	CompressionNone: noneCompressor{},
+	"gzip":          gzipCompressor{},
+	"zstd":          newZstdCompressor(),
+}
+
+// LookupCompressor returns the compressor registered under name. An empty
+// name means no compression, as for messages without the header field.
+func LookupCompressor(name string) (Compressor, error) {
+	if name == "" {
+		name = CompressionNone
+	}
+	c, ok := compressors[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCompressor, name)
+	}
+	return c, nil
+}
+
+type noneCompressor struct{}
+
+func (noneCompressor) Name() string                           { return CompressionNone }
+func (noneCompressor) Compress(data []byte) ([]byte, error)   { return data, nil }
+func (noneCompressor) Decompress(data []byte) ([]byte, error) { return data, nil }
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Name() string {
+	return "gzip"
+}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readLimited(r)
+}
+
+// zstdCompressor shares one encoder and decoder; both are safe for
+// concurrent EncodeAll/DecodeAll calls
+type zstdCompressor struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+func newZstdCompressor() zstdCompressor {
+	// Options are static and valid, so construction cannot fail
+	enc, _ := zstd.NewWriter(nil)
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	return zstdCompressor{enc: enc, dec: dec}
+}
+
+func (zstdCompressor) Name() string {
+	return "zstd"
+}
+
+func (c zstdCompressor) Compress(data []byte) ([]byte, error) {
+	return c.enc.EncodeAll(data, nil), nil
+}
+
+func (c zstdCompressor) Decompress(data []byte) ([]byte, error) {
+	out, err := c.dec.DecodeAll(data, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, ErrTooLarge
+	}
+	return out, err
+}
+
+// readLimited reads r to EOF, failing past maxDecompressedSize
+func readLimited(r io.Reader) ([]byte, error) {
+	out, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxDecompressedSize {
+		return nil, ErrTooLarge
+	}
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("pars message payload "), 100)
+
+	for _, name := range config.Compressions {
+		c, err := LookupCompressor(name)
+		if err != nil {
+			t.Fatalf("config algorithm %s not registered: %v", name, err)
+		}
+
+		compressed, err := c.Compress(plaintext)
+		if err != nil {
+			t.Fatalf("%s: compress: %v", name, err)
+		}
+		if name != CompressionNone && len(compressed) >= len(plaintext) {
+			t.Errorf("%s: expected repetitive input to shrink, got %d bytes", name, len(compressed))
+		}
+
+		got, err := c.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("%s: decompress: %v", name, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("%s: round trip mismatch", name)
+		}
+	}
+
+	if _, err := LookupCompressor("lz4"); !errors.Is(err, ErrUnknownCompressor) {
+		t.Errorf("expected ErrUnknownCompressor, got %v", err)
+	}
+}
+
+func TestDecompressTooLarge(t *testing.T) {
+	bomb := make([]byte, maxDecompressedSize+1)
+
+	for _, name := range []string{"gzip", "zstd"} {
+		c, _ := LookupCompressor(name)
+		compressed, err := c.Compress(bomb)
+		if err != nil {
+			t.Fatalf("%s: compress: %v", name, err)
+		}
+		if _, err := c.Decompress(compressed); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: expected ErrTooLarge, got %v", name, err)
+		}
+	}
+}
+
+func TestSendTagsCompression(t *testing.T) {
+	cfg := config.Default().Pars
+	cfg.Compression = "gzip"
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := loopback(t, m)
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if msg.Compression != "gzip" {
+		t.Errorf("expected gzip header, got %q", msg.Compression)
+	}
+
+	// A message sealed by the caller keeps its own header
+	presealed := testMessage()
+	presealed.Compression = "none"
+	if err := m.Send(context.Background(), presealed); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if presealed.Compression != "none" {
+		t.Errorf("expected caller's header kept, got %q", presealed.Compression)
+	}
+
+	msg.Compression = "lz4"
+	data, _ := EncodeMessage(msg, jsonCodec{})
+	if _, err := DecodeMessage(data); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage for unknown compression, got %v", err)
+	}
+
+	cfg.Compression = "lz4"
+	if _, err := NewMessenger(cfg, config.Default().Crypto); !errors.Is(err, ErrUnknownCompressor) {
+		t.Errorf("expected ErrUnknownCompressor, got %v", err)
+	}
+}
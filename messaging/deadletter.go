@@ -0,0 +1,176 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/storage"
+)
+
+var ErrNotDeadLettered = errors.New("message not in dead-letter queue")
+
+// DeadLetter is a message that failed every send attempt
+type DeadLetter struct {
+	Message  *Message  `json:"message"`
+	Reason   string    `json:"reason"` // Error from the final attempt
+	Attempts int       `json:"attempts"`
+	Time     time.Time `json:"time"`
+}
+
+// deadLetterQueue holds dead letters, oldest first, within the configured
+// size and retention
+type deadLetterQueue struct {
+	cfg config.DeadLetterConfig
+
+	mu      sync.Mutex
+	entries []DeadLetter
+}
+
+// add appends a dead letter, dropping the oldest beyond MaxEntries
+func (q *deadLetterQueue) add(dl DeadLetter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.entries = append(q.entries, dl)
+	if q.cfg.MaxEntries > 0 && len(q.entries) > q.cfg.MaxEntries {
+		q.entries = slices.Delete(q.entries, 0, len(q.entries)-q.cfg.MaxEntries)
+	}
+}
+
+// list prunes expired entries and returns the rest
+func (q *deadLetterQueue) list(now time.Time) []DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cfg.Retention > 0 {
+		cutoff := now.Add(-time.Duration(q.cfg.Retention) * time.Second)
+		q.entries = slices.DeleteFunc(q.entries, func(dl DeadLetter) bool {
+			return dl.Time.Before(cutoff)
+		})
+	}
+	return slices.Clone(q.entries)
+}
+
+// take removes and returns the dead letter for message id
+func (q *deadLetterQueue) take(id string) (DeadLetter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := slices.IndexFunc(q.entries, func(dl DeadLetter) bool {
+		return dl.Message.ID == id
+	})
+	if i < 0 {
+		return DeadLetter{}, false
+	}
+	dl := q.entries[i]
+	q.entries = slices.Delete(q.entries, i, i+1)
+	return dl, true
+}
+
+// permanentErrors are send failures that no retry can fix; a message
+// failing with one is dead-lettered at once
+var permanentErrors = []error{
+	ErrNoSigner,
+	ErrInvalidTTL,
+	ErrInvalidMessage,
+	ErrInvalidSessionID,
+	ErrInvalidSignature,
+	ErrInvalidPadding,
+	ErrUnknownRecipient,
+	ErrUnknownKEM,
+	ErrUnknownCompressor,
+	ErrUnknownCodec,
+	ErrTooLarge,
+	storage.ErrInvalidKey,
+}
+
+// permanent reports whether err is one of permanentErrors
+func permanent(err error) bool {
+	return slices.ContainsFunc(permanentErrors, func(target error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// deliver calls send up to MaxAttempts times, backing off between
+// attempts, and dead-letters msg if every attempt fails. A permanent
+// failure is dead-lettered without retrying.
+func (m *Messenger) deliver(ctx context.Context, msg *Message) error {
+	maxAttempts := max(m.cfg.DeadLetter.MaxAttempts, 1)
+	backoff := time.Duration(m.cfg.DeadLetter.InitialBackoffMs) * time.Millisecond
+	var (
+		err      error
+		attempts int
+	)
+	for attempts < maxAttempts {
+		attempts++
+		if err = m.send(ctx, msg); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			// Shutting down; the message did not fail on its own
+			return err
+		}
+		if permanent(err) || attempts == maxAttempts {
+			break
+		}
+		if err := sleepCtx(ctx, jitter(backoff)); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, time.Duration(m.cfg.DeadLetter.MaxBackoffMs)*time.Millisecond)
+	}
+
+	m.deadLetters.add(DeadLetter{
+		Message:  msg,
+		Reason:   err.Error(),
+		Attempts: attempts,
+		Time:     m.now(),
+	})
+	m.record(EventDeadLettered, msg)
+	return err
+}
+
+// jitter returns a random delay in [d/2, d), so senders failing together
+// do not retry in lockstep
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
+
+// sleepCtx waits for d or until ctx is done, returning ctx.Err() then
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DeadLetters returns the messages that exhausted their send attempts,
+// oldest first
+func (m *Messenger) DeadLetters() []DeadLetter {
+	return m.deadLetters.list(m.now())
+}
+
+// Replay removes message id from the dead-letter queue and delivers it
+// again. It is dead-lettered afresh if every attempt fails.
+func (m *Messenger) Replay(ctx context.Context, id string) error {
+	dl, ok := m.deadLetters.take(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotDeadLettered, id)
+	}
+	return m.deliver(ctx, dl.Message)
+}
@@ -0,0 +1,149 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestDeadLetterReplay(t *testing.T) {
+	cfg := config.Default().Pars
+	cfg.DeadLetter = config.DeadLetterConfig{MaxAttempts: 3, MaxEntries: 10, Retention: 60}
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+	now := time.Unix(1700000000, 0)
+	m.now = func() time.Time { return now }
+
+	errUnreachable := errors.New("recipient unreachable")
+	attempts := 0
+	m.send = func(ctx context.Context, msg *Message) error {
+		attempts++
+		return errUnreachable
+	}
+
+	done := make(chan error, 1)
+	if err := m.SendAsync(context.Background(), testMessage(), func(err error) { done <- err }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-done; !errors.Is(err, errUnreachable) {
+		t.Errorf("expected send error, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	dead := m.DeadLetters()
+	if len(dead) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(dead))
+	}
+	if dead[0].Message.ID != "msg-1" || dead[0].Reason != errUnreachable.Error() || dead[0].Attempts != 3 {
+		t.Errorf("unexpected dead letter: %+v", dead[0])
+	}
+
+	// Replay once the recipient is reachable again
+	m.send = func(ctx context.Context, msg *Message) error { return nil }
+	if err := m.Replay(context.Background(), "msg-1"); err != nil {
+		t.Errorf("replay: unexpected error: %v", err)
+	}
+	if dead := m.DeadLetters(); len(dead) != 0 {
+		t.Errorf("expected empty queue after replay, got %d", len(dead))
+	}
+	if err := m.Replay(context.Background(), "msg-1"); !errors.Is(err, ErrNotDeadLettered) {
+		t.Errorf("expected ErrNotDeadLettered, got %v", err)
+	}
+}
+
+func TestDeadLetterLimits(t *testing.T) {
+	q := &deadLetterQueue{cfg: config.DeadLetterConfig{MaxEntries: 2, Retention: 60}}
+	start := time.Unix(1700000000, 0)
+	for i, id := range []string{"a", "b", "c"} {
+		q.add(DeadLetter{Message: &Message{ID: id}, Time: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	got := q.list(start.Add(2 * time.Minute))
+	if len(got) != 2 || got[0].Message.ID != "b" || got[1].Message.ID != "c" {
+		t.Errorf("expected oldest dropped beyond MaxEntries, got %+v", got)
+	}
+	got = q.list(start.Add(2*time.Minute + 30*time.Second))
+	if len(got) != 1 || got[0].Message.ID != "c" {
+		t.Errorf("expected expired entries pruned, got %+v", got)
+	}
+}
+
+func TestDeliverClassifiesFailures(t *testing.T) {
+	cfg := config.Default().Pars
+	cfg.DeadLetter = config.DeadLetterConfig{MaxAttempts: 4, InitialBackoffMs: 1, MaxBackoffMs: 2}
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"transient", errors.New("recipient unreachable"), 4},
+		{"no signer", ErrNoSigner, 1},
+		{"invalid ttl", fmt.Errorf("%w: 0s", ErrInvalidTTL), 1},
+		{"invalid message", ErrInvalidMessage, 1},
+	}
+	for _, tt := range tests {
+		attempts := 0
+		m.send = func(ctx context.Context, msg *Message) error {
+			attempts++
+			return tt.err
+		}
+		msg := testMessage()
+		msg.ID = tt.name
+		if err := m.deliver(context.Background(), msg); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+		if attempts != tt.attempts {
+			t.Errorf("%s: expected %d attempts, got %d", tt.name, tt.attempts, attempts)
+		}
+		dead := m.DeadLetters()
+		if len(dead) == 0 || dead[len(dead)-1].Message.ID != tt.name || dead[len(dead)-1].Attempts != tt.attempts {
+			t.Errorf("%s: expected dead-lettered after %d attempts, got %+v", tt.name, tt.attempts, dead)
+		}
+	}
+}
+
+func TestDeliverBackoffCancelled(t *testing.T) {
+	cfg := config.Default().Pars
+	cfg.DeadLetter = config.DeadLetterConfig{MaxAttempts: 3, InitialBackoffMs: 60 * 1000, MaxBackoffMs: 60 * 1000}
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	m.send = func(ctx context.Context, msg *Message) error {
+		return errors.New("recipient unreachable")
+	}
+	if err := m.deliver(ctx, testMessage()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the backoff to end with ctx, got %v", err)
+	}
+	if dead := m.DeadLetters(); len(dead) != 0 {
+		t.Errorf("expected nothing dead-lettered on shutdown, got %d", len(dead))
+	}
+}
+
+func TestJitter(t *testing.T) {
+	const d = time.Second
+	for range 100 {
+		if got := jitter(d); got < d/2 || got >= d {
+			t.Fatalf("expected jitter in [%s, %s), got %s", d/2, d, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package messaging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestContentTypeSigned(t *testing.T) {
+	id, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{0x42}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signer, err := NewSoftwareSigner(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, codec := range []Codec{jsonCodec{}, newCBORCodec()} {
+		msg := testMessage()
+		msg.ContentType = ContentTypeControl
+		if err := msg.Sign(signer); err != nil {
+			t.Fatalf("%s: sign: %v", codec.Name(), err)
+		}
+
+		data, err := EncodeMessage(msg, codec)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", codec.Name(), err)
+		}
+		got, err := DecodeMessage(data)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", codec.Name(), err)
+		}
+		if got.ContentType != ContentTypeControl {
+			t.Errorf("%s: expected content type %q, got %q", codec.Name(), ContentTypeControl, got.ContentType)
+		}
+		if err := got.Verify(id.DSAPublicKey); err != nil {
+			t.Errorf("%s: verify: %v", codec.Name(), err)
+		}
+
+		got.ContentType = ContentTypeText
+		if err := got.Verify(id.DSAPublicKey); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature for altered content type, got %v", codec.Name(), err)
+		}
+	}
+}
+
+func TestFilterContentType(t *testing.T) {
+	text, control, untyped := testMessage(), testMessage(), testMessage()
+	text.ContentType = ContentTypeText
+	control.ContentType = ContentTypeControl
+
+	tests := []struct {
+		types []string
+		want  []*Message
+	}{
+		{nil, []*Message{text, control, untyped}},
+		{[]string{ContentTypeText}, []*Message{text}},
+		{[]string{ContentTypeText, ContentTypeControl}, []*Message{text, control}},
+		{[]string{"image/png"}, []*Message{}},
+	}
+
+	for _, tt := range tests {
+		got := filterContentType([]*Message{text, control, untyped}, tt.types)
+		if len(got) != len(tt.want) {
+			t.Errorf("%v: expected %d messages, got %d", tt.types, len(tt.want), len(got))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%v: message %d: expected %q, got %q", tt.types, i, tt.want[i].ContentType, got[i].ContentType)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package messaging
+
+import (
+	"crypto/sha3"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/luxfi/crypto/mldsa"
+
+	"github.com/parsdao/node/config"
+)
+
+// MinSeedSize is the minimum seed length accepted by GenerateIdentityFromSeed
+const MinSeedSize = 32
+
+// seedDomain separates seeded identity derivation from any other use of the seed
+const seedDomain = "pars-test-identity-v1"
+
+var ErrSeedTooShort = errors.New("identity seed too short")
+
+// GenerateIdentityFromSeed deterministically derives an identity from seed.
+//
+// UNSAFE FOR PRODUCTION: anyone who knows the seed can recompute every
+// secret key. Use it only for reproducible tests and documentation examples.
+func GenerateIdentityFromSeed(seed []byte) (*Identity, error) {
+	if len(seed) < MinSeedSize {
+		return nil, fmt.Errorf("%w: got %d bytes, need at least %d", ErrSeedTooShort, len(seed), MinSeedSize)
+	}
+
+	xof := sha3.NewSHAKE256()
+	xof.Write([]byte(seedDomain))
+	xof.Write(seed)
+
+	return identityFromReader(xof, kems[DefaultKEMScheme])
+}
+
+// identityFromReader generates kem and ML-DSA-65 keypairs from r
+func identityFromReader(r io.Reader, kem KEM) (*Identity, error) {
+	kemPub, kemPriv, err := kem.GenerateKeyPair(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate KEM keypair: %w", err)
+	}
+
+	dsaPriv, err := mldsa.GenerateKey(r, mldsa.MLDSA65)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DSA keypair: %w", err)
+	}
+
+	id := &Identity{
+		KEMPublicKey: kemPub,
+		KEMSecretKey: kemPriv,
+		DSAPublicKey: dsaPriv.PublicKey.Bytes(),
+		DSASecretKey: dsaPriv.Bytes(),
+	}
+	id.SessionID, err = DeriveSessionID(id.KEMPublicKey, id.DSAPublicKey, SessionIDHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// LoadOrCreateIdentity reads the node identity stored at path, generating
+// and saving a new one with crypto.KEMScheme if the file does not exist.
+// An existing identity keeps its scheme. The file holds secret keys and is
+// written with mode 0600.
+func LoadOrCreateIdentity(path string, crypto config.CryptoConfig) (*Identity, error) {
+	if path == "" {
+		return nil, errors.New("identity file not set")
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var id Identity
+		if err := json.Unmarshal(data, &id); err != nil {
+			return nil, fmt.Errorf("failed to parse identity file: %w", err)
+		}
+		derived, err := DeriveSessionID(id.KEMPublicKey, id.DSAPublicKey, SessionIDHex)
+		if err != nil || derived != id.SessionID {
+			return nil, fmt.Errorf("%w: identity file %s does not match its keys", ErrInvalidSessionID, path)
+		}
+		return &id, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read identity file: %w", err)
+	}
+
+	id, err := GenerateIdentity(crypto)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(id); err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create identity directory: %w", err)
+	}
+	// Write a temp file and link it into place, so a failed write leaves
+	// no truncated identity behind and a concurrently created one is never
+	// overwritten
+	f, err := os.CreateTemp(dir, ".identity-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write identity file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write identity file: %w", err)
+	}
+	if err := os.Link(f.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to create identity file: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,121 @@
+package messaging
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestGenerateIdentityFromSeed(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, MinSeedSize)
+
+	a, err := GenerateIdentityFromSeed(seed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := GenerateIdentityFromSeed(seed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if a.SessionID != b.SessionID {
+		t.Errorf("same seed produced different session IDs: %s != %s", a.SessionID, b.SessionID)
+	}
+	if !bytes.Equal(a.KEMSecretKey, b.KEMSecretKey) || !bytes.Equal(a.DSASecretKey, b.DSASecretKey) {
+		t.Error("same seed produced different secret keys")
+	}
+	if err := ValidateSessionID(a.SessionID); err != nil {
+		t.Errorf("derived session ID invalid: %v", err)
+	}
+
+	other, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{0x43}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.SessionID == a.SessionID {
+		t.Error("different seeds produced the same session ID")
+	}
+	if bytes.Equal(other.KEMPublicKey, a.KEMPublicKey) || bytes.Equal(other.DSAPublicKey, a.DSAPublicKey) {
+		t.Error("different seeds produced the same public keys")
+	}
+}
+
+func TestGenerateIdentityFromSeedTooShort(t *testing.T) {
+	if _, err := GenerateIdentityFromSeed(make([]byte, MinSeedSize-1)); !errors.Is(err, ErrSeedTooShort) {
+		t.Errorf("expected ErrSeedTooShort, got %v", err)
+	}
+}
+
+func TestGenerateIdentity(t *testing.T) {
+	a, err := GenerateIdentity(config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := GenerateIdentity(config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(a.SessionID, "07") {
+		t.Errorf("expected 07 prefix, got %s", a.SessionID)
+	}
+	for name, key := range map[string][]byte{
+		"KEM public": a.KEMPublicKey, "KEM secret": a.KEMSecretKey,
+		"DSA public": a.DSAPublicKey, "DSA secret": a.DSASecretKey,
+	} {
+		if len(key) == 0 {
+			t.Errorf("%s key empty", name)
+		}
+	}
+
+	// The session ID is a pure function of the public keys
+	again, err := DeriveSessionID(a.KEMPublicKey, a.DSAPublicKey, SessionIDHex)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again != a.SessionID {
+		t.Errorf("session ID not deterministic: %s != %s", again, a.SessionID)
+	}
+
+	if a.SessionID == b.SessionID || bytes.Equal(a.DSASecretKey, b.DSASecretKey) {
+		t.Error("two identities are the same")
+	}
+}
+
+func TestLoadOrCreateIdentity(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys", "identity.json")
+
+	created, err := LoadOrCreateIdentity(path, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	loaded, err := LoadOrCreateIdentity(path, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.SessionID != created.SessionID || !bytes.Equal(loaded.DSASecretKey, created.DSASecretKey) {
+		t.Error("expected the saved identity loaded back")
+	}
+
+	// Only the identity itself is left in the directory
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "identity.json" {
+		t.Errorf("expected only identity.json, got %v", entries)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", fi.Mode().Perm())
+	}
+}
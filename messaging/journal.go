@@ -0,0 +1,155 @@
+package messaging
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/parsdao/node/config"
+)
+
+// JournalEvent is a step in a message's lifecycle
+type JournalEvent string
+
+const (
+	EventEnqueued     JournalEvent = "enqueued"
+	EventStored       JournalEvent = "stored"
+	EventReplicated   JournalEvent = "replicated"
+	EventDelivered    JournalEvent = "delivered"
+	EventExpired      JournalEvent = "expired"
+	EventDeadLettered JournalEvent = "dead-lettered"
+)
+
+// JournalEntry is one recorded message event. Entries carry only
+// envelope metadata, never plaintext or ciphertext.
+type JournalEntry struct {
+	Time        time.Time    `json:"time"`
+	Event       JournalEvent `json:"event"`
+	MessageID   string       `json:"messageId"`
+	SenderID    string       `json:"senderId,omitempty"`
+	RecipientID string       `json:"recipientId,omitempty"`
+	Size        int          `json:"size,omitempty"` // Ciphertext bytes
+}
+
+// Journal appends message events to a size-rotated file for postmortems
+type Journal struct {
+	mu  sync.Mutex
+	out *lumberjack.Logger
+	now func() time.Time
+}
+
+// OpenJournal opens the journal file described by cfg
+func OpenJournal(cfg config.JournalConfig) (*Journal, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("journal path not set")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	return &Journal{
+		out: &lumberjack.Logger{
+			Filename:   cfg.Path,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Record appends an event for msg
+func (j *Journal) Record(event JournalEvent, msg *Message) error {
+	line, err := json.Marshal(JournalEntry{
+		Time:        j.now().UTC(),
+		Event:       event,
+		MessageID:   msg.ID,
+		SenderID:    msg.SenderID,
+		RecipientID: msg.RecipientID,
+		Size:        len(msg.Ciphertext),
+	})
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.out.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	return j.out.Close()
+}
+
+// ReplayJournal reconstructs the timeline of messageID from the journal
+// at path and its rotated backups, ordered oldest first
+func ReplayJournal(path, messageID string) ([]JournalEntry, error) {
+	files, err := journalFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []JournalEntry
+	for _, file := range files {
+		found, err := scanJournal(file, messageID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+
+	sort.SliceStable(entries, func(i, k int) bool {
+		return entries[i].Time.Before(entries[k].Time)
+	})
+	return entries, nil
+}
+
+// journalFiles returns the rotated backups of path followed by path itself
+func journalFiles(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(path, ext)
+
+	backups, err := filepath.Glob(prefix + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(backups)
+
+	if _, err := os.Stat(path); err == nil {
+		backups = append(backups, path)
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no journal found at %s", path)
+	}
+	return backups, nil
+}
+
+func scanJournal(file, messageID string) ([]JournalEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, line, err)
+		}
+		if entry.MessageID == messageID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
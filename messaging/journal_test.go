@@ -0,0 +1,114 @@
+package messaging
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/storage"
+)
+
+func TestJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal", "messages.log")
+
+	cfg := config.Default().Pars
+	cfg.Journal = config.JournalConfig{Enabled: true, Path: path, MaxSizeMB: 1}
+
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock := time.Unix(1700000000, 0)
+	m.journal.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	node, err := storage.NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.SetStorage(node)
+	m.now = func() time.Time { return testMessage().Timestamp }
+	sender, signer := testContact(t, m, 0x53)
+
+	msg := testMessage()
+	msg.SenderID = sender
+	other := testMessage()
+	other.ID = "msg-2"
+	other.SenderID = sender
+	for _, msg := range []*Message{msg, other} {
+		if err := msg.Sign(signer); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatalf("send %s: %v", msg.ID, err)
+		}
+	}
+	if _, err := m.Receive(context.Background(), testRecipient); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	m.Stop()
+
+	entries, err := ReplayJournal(path, msg.ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	want := []JournalEvent{EventEnqueued, EventStored, EventDelivered}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i, entry := range entries {
+		if entry.Event != want[i] {
+			t.Errorf("entry %d: expected %s, got %s", i, want[i], entry.Event)
+		}
+		if entry.MessageID != msg.ID || entry.RecipientID != msg.RecipientID {
+			t.Errorf("entry %d: wrong message metadata %+v", i, entry)
+		}
+		if entry.Size != len(msg.Ciphertext) {
+			t.Errorf("entry %d: expected size %d, got %d", i, len(msg.Ciphertext), entry.Size)
+		}
+		if i > 0 && !entry.Time.After(entries[i-1].Time) {
+			t.Errorf("entry %d: timeline out of order", i)
+		}
+	}
+}
+
+func TestJournalReplayAcrossRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.log")
+
+	j, err := OpenJournal(config.JournalConfig{Path: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := testMessage()
+	if err := j.Record(EventEnqueued, msg); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := j.out.Rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if err := j.Record(EventDelivered, msg); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	j.Close()
+
+	entries, err := ReplayJournal(path, msg.ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Event != EventEnqueued || entries[1].Event != EventDelivered {
+		t.Errorf("expected enqueued then delivered across rotation, got %+v", entries)
+	}
+}
+
+func TestReplayJournalMissing(t *testing.T) {
+	if _, err := ReplayJournal(filepath.Join(t.TempDir(), "none.log"), "msg-1"); err == nil {
+		t.Error("expected error for missing journal")
+	}
+}
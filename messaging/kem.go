@@ -0,0 +1,111 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/luxfi/crypto/mlkem"
+
+	"github.com/parsdao/node/config"
+)
+
+// DefaultKEMScheme is used when no scheme is configured and assumed for
+// messages that predate the kemScheme header field
+const DefaultKEMScheme = "ML-KEM-768"
+
+var ErrUnknownKEM = errors.New("unknown KEM scheme")
+
+// KEM is a key encapsulation mechanism operating on serialized keys
+type KEM interface {
+	// Name returns the scheme name recorded in message headers
+	Name() string
+
+	// PublicKeySize returns the length of a serialized public key, which
+	// identifies the scheme of a contact's key
+	PublicKeySize() int
+
+	// GenerateKeyPair generates a keypair using randomness from r
+	GenerateKeyPair(r io.Reader) (publicKey, secretKey []byte, err error)
+
+	// Encapsulate returns a ciphertext and the shared secret it encapsulates
+	Encapsulate(publicKey []byte) (ciphertext, sharedSecret []byte, err error)
+
+	// Decapsulate recovers the shared secret from ciphertext
+	Decapsulate(secretKey, ciphertext []byte) ([]byte, error)
+}
+
+// kems holds the supported schemes by name
+var kems = map[string]KEM{
+	"ML-KEM-512":  mlkemScheme{name: "ML-KEM-512", mode: mlkem.MLKEM512},
+	"ML-KEM-768":  mlkemScheme{name: "ML-KEM-768", mode: mlkem.MLKEM768},
+	"ML-KEM-1024": mlkemScheme{name: "ML-KEM-1024", mode: mlkem.MLKEM1024},
+}
+
+// LookupKEM returns the KEM registered under name
+func LookupKEM(name string) (KEM, error) {
+	k, ok := kems[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKEM, name)
+	}
+	return k, nil
+}
+
+// configuredKEM returns the KEM named by crypto.KEMScheme, or
+// DefaultKEMScheme if it is unset
+func configuredKEM(crypto config.CryptoConfig) (KEM, error) {
+	if crypto.KEMScheme == "" {
+		return LookupKEM(DefaultKEMScheme)
+	}
+	return LookupKEM(crypto.KEMScheme)
+}
+
+// kemForPublicKey returns the KEM whose public keys are as long as
+// publicKey. Messages are sealed with the scheme of the recipient's key,
+// whatever scheme the sender's own identity uses.
+func kemForPublicKey(publicKey []byte) (KEM, error) {
+	for _, k := range kems {
+		if k.PublicKeySize() == len(publicKey) {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no scheme has %d-byte public keys", ErrUnknownKEM, len(publicKey))
+}
+
+// mlkemScheme is a FIPS 203 ML-KEM parameter set
+type mlkemScheme struct {
+	name string
+	mode mlkem.Mode
+}
+
+func (s mlkemScheme) Name() string {
+	return s.name
+}
+
+func (s mlkemScheme) PublicKeySize() int {
+	return mlkem.GetPublicKeySize(s.mode)
+}
+
+func (s mlkemScheme) GenerateKeyPair(r io.Reader) ([]byte, []byte, error) {
+	pub, priv, err := mlkem.GenerateKeyPair(r, s.mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub.Bytes(), priv.Bytes(), nil
+}
+
+func (s mlkemScheme) Encapsulate(publicKey []byte) ([]byte, []byte, error) {
+	pub, err := mlkem.PublicKeyFromBytes(publicKey, s.mode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s public key: %w", s.name, err)
+	}
+	return pub.Encapsulate()
+}
+
+func (s mlkemScheme) Decapsulate(secretKey, ciphertext []byte) ([]byte, error) {
+	priv, err := mlkem.PrivateKeyFromBytes(secretKey, s.mode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s secret key: %w", s.name, err)
+	}
+	return priv.Decapsulate(ciphertext)
+}
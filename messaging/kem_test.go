@@ -0,0 +1,178 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestKEMRoundTrip(t *testing.T) {
+	for _, name := range []string{"ML-KEM-512", "ML-KEM-1024"} {
+		k, err := LookupKEM(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		pub, priv, err := k.GenerateKeyPair(rand.Reader)
+		if err != nil {
+			t.Fatalf("%s: keygen: %v", name, err)
+		}
+		ct, ss, err := k.Encapsulate(pub)
+		if err != nil {
+			t.Fatalf("%s: encapsulate: %v", name, err)
+		}
+		got, err := k.Decapsulate(priv, ct)
+		if err != nil {
+			t.Fatalf("%s: decapsulate: %v", name, err)
+		}
+		if !bytes.Equal(got, ss) {
+			t.Errorf("%s: shared secrets differ", name)
+		}
+	}
+}
+
+func TestKEMSchemesMismatch(t *testing.T) {
+	k512, _ := LookupKEM("ML-KEM-512")
+	k1024, _ := LookupKEM("ML-KEM-1024")
+
+	pub, _, err := k512.GenerateKeyPair(rand.Reader)
+	if err != nil {
+		t.Fatalf("keygen: %v", err)
+	}
+	if _, _, err := k1024.Encapsulate(pub); err == nil {
+		t.Error("expected error encapsulating to a key of another scheme")
+	}
+}
+
+func TestKEMRegistryMatchesConfig(t *testing.T) {
+	for _, name := range config.KEMSchemes {
+		if _, err := LookupKEM(name); err != nil {
+			t.Errorf("config scheme %s not registered: %v", name, err)
+		}
+	}
+	if _, err := LookupKEM("Kyber768"); !errors.Is(err, ErrUnknownKEM) {
+		t.Errorf("expected ErrUnknownKEM, got %v", err)
+	}
+}
+
+func TestGenerateIdentityKEMScheme(t *testing.T) {
+	tests := []struct {
+		scheme string
+		want   string
+		err    error
+	}{
+		{"", DefaultKEMScheme, nil},
+		{"ML-KEM-512", "ML-KEM-512", nil},
+		{"ML-KEM-1024", "ML-KEM-1024", nil},
+		{"Kyber768", "", ErrUnknownKEM},
+	}
+	for _, tt := range tests {
+		crypto := config.Default().Crypto
+		crypto.KEMScheme = tt.scheme
+		if _, err := NewMessenger(config.Default().Pars, crypto); !errors.Is(err, tt.err) {
+			t.Errorf("%q: expected messenger error %v, got %v", tt.scheme, tt.err, err)
+		}
+		id, err := GenerateIdentity(crypto)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%q: expected %v, got %v", tt.scheme, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		k, err := kemForPublicKey(id.KEMPublicKey)
+		if err != nil || k.Name() != tt.want {
+			t.Errorf("%q: expected a %s key, got %v (%v)", tt.scheme, tt.want, k, err)
+		}
+	}
+}
+
+func TestSendTagsKEMScheme(t *testing.T) {
+	crypto := config.Default().Crypto
+	crypto.KEMScheme = "ML-KEM-1024"
+	m, err := NewMessenger(config.Default().Pars, crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Identities generated under the config round-trip through Send and Open
+	alice, err := GenerateIdentity(crypto)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	bob, err := GenerateIdentity(crypto)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	signer, err := NewSoftwareSigner(alice)
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	m.SetSigner(signer)
+	for _, id := range []*Identity{alice, bob} {
+		if err := m.AddContact(id.SessionID, id.KEMPublicKey, id.DSAPublicKey); err != nil {
+			t.Fatalf("add contact: %v", err)
+		}
+	}
+
+	msg := &Message{ID: "m1", SenderID: alice.SessionID, RecipientID: bob.SessionID, Plaintext: []byte("hello"), TTL: 3600}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if msg.KEMScheme != "ML-KEM-1024" {
+		t.Errorf("expected ML-KEM-1024 header, got %q", msg.KEMScheme)
+	}
+	got, err := m.Open(msg, bob)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !bytes.Equal(got, []byte("hello")) {
+		t.Errorf("expected hello, got %q", got)
+	}
+
+	data, err := EncodeMessage(msg, jsonCodec{})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.KEMScheme != "ML-KEM-1024" {
+		t.Errorf("expected scheme to survive encoding, got %q", decoded.KEMScheme)
+	}
+
+	msg.KEMScheme = "Kyber768"
+	data, _ = EncodeMessage(msg, jsonCodec{})
+	if _, err := DecodeMessage(data); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage for unknown scheme, got %v", err)
+	}
+}
+
+func TestSealUsesRecipientScheme(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	crypto := config.Default().Crypto
+	crypto.KEMScheme = "ML-KEM-512"
+	recipient, err := GenerateIdentity(crypto)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if err := m.AddContact(recipient.SessionID, recipient.KEMPublicKey, recipient.DSAPublicKey); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+
+	msg := &Message{ID: "m1", RecipientID: recipient.SessionID, Plaintext: []byte("hello")}
+	if err := m.seal(msg); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if msg.KEMScheme != "ML-KEM-512" {
+		t.Errorf("expected the recipient's ML-KEM-512, got %q", msg.KEMScheme)
+	}
+}
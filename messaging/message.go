@@ -0,0 +1,82 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// PQPrefix marks a post-quantum session ID (ML-KEM + ML-DSA)
+	PQPrefix = "07"
+
+	// LegacyPrefix marks a legacy session ID (X25519 + Ed25519)
+	LegacyPrefix = "05"
+
+	// sessionIDLen is the hex prefix plus a hex Blake2b-256 digest
+	sessionIDLen = 2 + 64
+)
+
+var (
+	ErrInvalidMessage   = errors.New("invalid message")
+	ErrInvalidSessionID = errors.New("invalid session ID")
+)
+
+// ValidateSessionID checks that id is a prefixed Blake2b-256 digest in
+// any supported encoding
+func ValidateSessionID(id string) error {
+	_, err := ParseSessionID(id)
+	return err
+}
+
+// EncodeMessage serializes a message for storage or transmission,
+// prefixed with the codec's format byte
+func EncodeMessage(msg *Message, codec Codec) ([]byte, error) {
+	data, err := codec.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{codec.Format()}, data...), nil
+}
+
+// DecodeMessage parses and validates an untrusted serialized message,
+// using the codec named by its format byte
+func DecodeMessage(data []byte) (*Message, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidMessage)
+	}
+	codec, err := codecForFormat(data[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	var msg Message
+	if err := codec.Unmarshal(data[1:], &msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	if msg.ID == "" {
+		return nil, fmt.Errorf("%w: missing id", ErrInvalidMessage)
+	}
+	if err := ValidateSessionID(msg.SenderID); err != nil {
+		return nil, fmt.Errorf("%w: sender: %v", ErrInvalidMessage, err)
+	}
+	if err := ValidateSessionID(msg.RecipientID); err != nil {
+		return nil, fmt.Errorf("%w: recipient: %v", ErrInvalidMessage, err)
+	}
+	if msg.KEMScheme != "" {
+		if _, err := LookupKEM(msg.KEMScheme); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+	}
+	if _, err := LookupCompressor(msg.Compression); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	if len(msg.Ciphertext) == 0 {
+		return nil, fmt.Errorf("%w: empty ciphertext", ErrInvalidMessage)
+	}
+	if msg.TTL < 0 {
+		return nil, fmt.Errorf("%w: negative ttl", ErrInvalidMessage)
+	}
+
+	return &msg, nil
+}
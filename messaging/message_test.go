@@ -0,0 +1,212 @@
+package messaging
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+var (
+	testSender    = PQPrefix + strings.Repeat("ab", 32)
+	testRecipient = PQPrefix + strings.Repeat("cd", 32)
+)
+
+func testMessage() *Message {
+	return &Message{
+		ID:          "msg-1",
+		SenderID:    testSender,
+		RecipientID: testRecipient,
+		Ciphertext:  []byte("ciphertext"),
+		Signature:   []byte("signature"),
+		Timestamp:   time.Unix(1700000000, 0).UTC(),
+		TTL:         3600,
+	}
+}
+
+func TestValidateSessionID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{testSender, true},
+		{LegacyPrefix + strings.Repeat("00", 32), true},
+		{"", false},
+		{"06" + strings.Repeat("00", 32), false},
+		{PQPrefix + strings.Repeat("zz", 32), false},
+		{PQPrefix + strings.Repeat("00", 31), false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateSessionID(tt.id)
+		if (err == nil) != tt.valid {
+			t.Errorf("id %q: expected valid=%v, got %v", tt.id, tt.valid, err)
+		}
+	}
+}
+
+func TestDecodeMessageRoundTrip(t *testing.T) {
+	want := testMessage()
+	want.Timestamp = want.Timestamp.Add(123456789) // Sub-second precision
+
+	for _, codec := range []Codec{jsonCodec{}, newCBORCodec()} {
+		data, err := EncodeMessage(want, codec)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", codec.Name(), err)
+		}
+
+		got, err := DecodeMessage(data)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", codec.Name(), err)
+		}
+
+		if got.ID != want.ID || got.SenderID != want.SenderID || got.RecipientID != want.RecipientID {
+			t.Errorf("%s: header mismatch: got %+v", codec.Name(), got)
+		}
+		if string(got.Ciphertext) != string(want.Ciphertext) {
+			t.Errorf("%s: ciphertext mismatch: got %q", codec.Name(), got.Ciphertext)
+		}
+		if !got.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("%s: timestamp mismatch: got %v", codec.Name(), got.Timestamp)
+		}
+	}
+}
+
+func TestMessengerSerialization(t *testing.T) {
+	for _, name := range config.Serializations {
+		cfg := config.Default().Pars
+		cfg.Serialization = name
+		m, err := NewMessenger(cfg, config.Default().Crypto)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		data, err := m.Encode(testMessage())
+		if err != nil {
+			t.Fatalf("%s: encode: %v", name, err)
+		}
+		codec, _ := LookupCodec(name)
+		if data[0] != codec.Format() {
+			t.Errorf("%s: expected format byte 0x%02x, got 0x%02x", name, codec.Format(), data[0])
+		}
+		if _, err := DecodeMessage(data); err != nil {
+			t.Errorf("%s: decode: %v", name, err)
+		}
+	}
+
+	cfg := config.Default().Pars
+	cfg.Serialization = "protobuf"
+	if _, err := NewMessenger(cfg, config.Default().Crypto); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("expected ErrUnknownCodec, got %v", err)
+	}
+}
+
+func TestDecodeMessageFormatMismatch(t *testing.T) {
+	jsonData, err := EncodeMessage(testMessage(), jsonCodec{})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	cborData, err := EncodeMessage(testMessage(), newCBORCodec())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if len(cborData) >= len(jsonData) {
+		t.Errorf("expected CBOR smaller than JSON, got %d >= %d bytes", len(cborData), len(jsonData))
+	}
+
+	tests := map[string][]byte{
+		"json labelled cbor":  append([]byte{formatCBOR}, jsonData[1:]...),
+		"cbor labelled json":  append([]byte{formatJSON}, cborData[1:]...),
+		"unknown format byte": append([]byte{0x7f}, jsonData[1:]...),
+		"unprefixed json":     jsonData[1:],
+	}
+	for name, data := range tests {
+		if _, err := DecodeMessage(data); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("%s: expected ErrInvalidMessage, got %v", name, err)
+		}
+	}
+}
+
+func TestDecodeMessageInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Message)
+	}{
+		{"missing id", func(m *Message) { m.ID = "" }},
+		{"bad sender", func(m *Message) { m.SenderID = "07xyz" }},
+		{"bad recipient", func(m *Message) { m.RecipientID = "" }},
+		{"empty ciphertext", func(m *Message) { m.Ciphertext = nil }},
+		{"negative ttl", func(m *Message) { m.TTL = -1 }},
+	}
+
+	for _, tt := range tests {
+		msg := testMessage()
+		tt.mutate(msg)
+
+		data, err := EncodeMessage(msg, jsonCodec{})
+		if err != nil {
+			t.Fatalf("%s: encode: %v", tt.name, err)
+		}
+		if _, err := DecodeMessage(data); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("%s: expected ErrInvalidMessage, got %v", tt.name, err)
+		}
+	}
+}
+
+func FuzzDecodeMessage(f *testing.F) {
+	valid, err := EncodeMessage(testMessage(), jsonCodec{})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(valid)
+
+	validCBOR, err := EncodeMessage(testMessage(), newCBORCodec())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(validCBOR)
+
+	legacy := testMessage()
+	legacy.SenderID = LegacyPrefix + strings.Repeat("00", 32)
+	legacyData, err := EncodeMessage(legacy, jsonCodec{})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(legacyData)
+
+	// Malformed seeds
+	f.Add([]byte{})
+	for _, seed := range []string{
+		"null",
+		"{",
+		`{"id":1}`,
+		`{"id":"x","ciphertext":"not base64!"}`,
+		`{"id":"x","timestamp":"yesterday"}`,
+		`[{"id":"x"}]`,
+	} {
+		f.Add(append([]byte{formatJSON}, seed...))
+	}
+	f.Add([]byte(`{"id":"x"}`))
+	f.Add(valid[:len(valid)/2])
+	f.Add(validCBOR[:len(validCBOR)/2])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, err := DecodeMessage(data)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidMessage) {
+				t.Fatalf("unexpected error type: %v", err)
+			}
+			return
+		}
+
+		// Anything accepted must satisfy the validation invariants
+		if msg.ID == "" || len(msg.Ciphertext) == 0 || msg.TTL < 0 {
+			t.Fatalf("invalid message accepted: %+v", msg)
+		}
+		if ValidateSessionID(msg.SenderID) != nil || ValidateSessionID(msg.RecipientID) != nil {
+			t.Fatalf("invalid session ID accepted: %+v", msg)
+		}
+	})
+}
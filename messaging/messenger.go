@@ -3,9 +3,15 @@ package messaging
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/metrics"
+	"github.com/parsdao/node/storage"
 )
 
 // Message represents an encrypted message
@@ -13,23 +19,121 @@ type Message struct {
 	ID          string    `json:"id"`
 	SenderID    string    `json:"senderId"` // "07" + Blake2b(KEM_pk || DSA_pk)
 	RecipientID string    `json:"recipientId"`
-	Ciphertext  []byte    `json:"ciphertext"` // ML-KEM encapsulated + XChaCha20
-	Signature   []byte    `json:"signature"`  // ML-DSA-65 signature
+	KEMScheme   string    `json:"kemScheme,omitempty"`   // KEM used for Ciphertext, e.g. "ML-KEM-768"
+	Compression string    `json:"compression,omitempty"` // Plaintext compression, e.g. "zstd"
+	ContentType string    `json:"contentType,omitempty"` // Payload type, e.g. "text/plain"; signed
+	Ciphertext  []byte    `json:"ciphertext"`            // ML-KEM encapsulated + XChaCha20
+	Signature   []byte    `json:"signature"`             // ML-DSA-65 signature
 	Timestamp   time.Time `json:"timestamp"`
-	TTL         int64     `json:"ttl"` // Time to live in seconds
+	TTL         int64     `json:"ttl"`                  // Time to live in seconds
+	RequireAck  bool      `json:"requireAck,omitempty"` // Recipient must acknowledge; signed
+
+	// Plaintext is sealed into Ciphertext by Send; never serialized
+	Plaintext []byte `json:"-"`
 }
 
+var (
+	ErrInvalidTTL = errors.New("message TTL out of range")
+	ErrClockSkew  = errors.New("message timestamp too far in the future")
+)
+
 // Messenger handles PQ-encrypted messaging
 type Messenger struct {
-	cfg     config.ParsConfig
-	running bool
+	cfg        config.ParsConfig
+	compressor Compressor
+	codec      Codec
+	workers    *WorkerPool
+	plainSizes *metrics.Histogram // Plaintext sizes sealed by Send
+	sizes      *metrics.Histogram // Sealed message sizes sent
+	journal    *Journal
+	signer     Signer        // Node key for SignMessage; nil until SetSigner
+	storage    *storage.Node // Where Send hands sealed messages; nil until SetStorage
+	running    bool
+
+	// Public keys of known sessions
+	contacts contactBook
+
+	// Messages that exhausted their send attempts
+	deadLetters *deadLetterQueue
+
+	// Sessions restricted to particular senders
+	allowlists senderAllowlists
+
+	// Sent messages awaiting acknowledgement
+	acks pendingAcks
+
+	// send is one delivery attempt, replaceable in tests
+	send func(ctx context.Context, msg *Message) error
+
+	// now is the messenger clock, replaceable in tests
+	now func() time.Time
+}
+
+// NewMessenger creates a new messenger. crypto.KEMScheme must name a
+// supported KEM; outgoing messages are sealed with the scheme of each
+// recipient's key.
+func NewMessenger(cfg config.ParsConfig, crypto config.CryptoConfig) (*Messenger, error) {
+	if _, err := configuredKEM(crypto); err != nil {
+		return nil, err
+	}
+	compressor, err := LookupCompressor(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := LookupCodec(cfg.Serialization)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Messenger{
+		cfg:        cfg,
+		compressor: compressor,
+		codec:      codec,
+		workers:    NewWorkerPool(cfg.MaxWorkers),
+		plainSizes: metrics.NewSizeHistogram(),
+		sizes:      metrics.NewSizeHistogram(),
+		now:        time.Now,
+
+		deadLetters: &deadLetterQueue{cfg: cfg.DeadLetter},
+	}
+	m.send = m.Send
+
+	if cfg.Journal.Enabled {
+		journal, err := OpenJournal(cfg.Journal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open message journal: %w", err)
+		}
+		m.journal = journal
+	}
+
+	return m, nil
+}
+
+// SetSigner sets the key SignMessage signs with, e.g. from NewSoftwareSigner
+func (m *Messenger) SetSigner(signer Signer) {
+	m.signer = signer
+}
+
+// SignMessage signs msg with the node key set by SetSigner
+func (m *Messenger) SignMessage(msg *Message) error {
+	if m.signer == nil {
+		return ErrNoSigner
+	}
+	return msg.Sign(m.signer)
+}
+
+// SetStorage sets the storage node Send hands sealed messages to
+func (m *Messenger) SetStorage(node *storage.Node) {
+	m.storage = node
 }
 
-// NewMessenger creates a new messenger
-func NewMessenger(cfg config.ParsConfig) (*Messenger, error) {
-	return &Messenger{
-		cfg: cfg,
-	}, nil
+// SessionID returns id's session ID in the configured encoding
+func (m *Messenger) SessionID(id *Identity) (string, error) {
+	raw, err := ParseSessionID(id.SessionID)
+	if err != nil {
+		return "", err
+	}
+	return FormatSessionID(raw, m.cfg.Session.IDEncoding)
 }
 
 // Start starts the messenger
@@ -38,42 +142,220 @@ func (m *Messenger) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the messenger
+// Flush stops accepting async sends and waits for those already queued
+// to finish, until ctx is done
+func (m *Messenger) Flush(ctx context.Context) error {
+	return m.workers.Drain(ctx)
+}
+
+// Stop stops the messenger. Queued async sends are dropped, completing
+// with ErrPoolClosed; call Flush first to deliver them.
 func (m *Messenger) Stop() {
 	m.running = false
+	m.workers.Close()
+	if m.journal != nil {
+		m.journal.Close()
+	}
 }
 
-// Send sends an encrypted message
-// Uses ML-KEM-768 for key encapsulation, XChaCha20-Poly1305 for encryption,
-// and ML-DSA-65 for signing
+// Send sends an encrypted message. If msg.Ciphertext is empty, msg.Plaintext
+// is sealed to the recipient's contact keys (see seal) and the message
+// signed with the node key; msg is only updated once both succeed. A
+// message sealed by the caller is sent as is and must already be signed.
+// The message is then stored under its recipient's session for routing.
 func (m *Messenger) Send(ctx context.Context, msg *Message) error {
-	// TODO: Implement using lux/crypto via pars::crypto adapter
-	// 1. ML-KEM encapsulate to recipient's public key
-	// 2. Derive symmetric key
-	// 3. Encrypt with XChaCha20-Poly1305
-	// 4. Sign with ML-DSA-65
-	// 5. Route through onion network
+	if err := m.checkTTL(msg.TTL); err != nil {
+		return err
+	}
+	if len(msg.Ciphertext) == 0 && msg.Plaintext != nil {
+		m.plainSizes.Observe(uint64(len(msg.Plaintext)))
+		sealed := *msg
+		if sealed.Timestamp.IsZero() {
+			sealed.Timestamp = m.now()
+		}
+		if err := m.seal(&sealed); err != nil {
+			return err
+		}
+		if err := m.SignMessage(&sealed); err != nil {
+			return err
+		}
+		*msg = sealed
+	}
+	if len(msg.Signature) == 0 {
+		return fmt.Errorf("%w: message %s is unsigned", ErrInvalidSignature, msg.ID)
+	}
+	m.sizes.Observe(uint64(len(msg.Ciphertext)))
+	m.record(EventEnqueued, msg)
+
+	if m.storage == nil {
+		return nil
+	}
+	recipient, err := canonicalSessionID(msg.RecipientID)
+	if err != nil {
+		return err
+	}
+	key, err := storage.MessageKey(recipient, msg.ID)
+	if err != nil {
+		return err
+	}
+	data, err := m.Encode(msg)
+	if err != nil {
+		return err
+	}
+	// TODO: Route through the onion network instead of the local node
+	if err := m.storage.Store(ctx, key, data, msg.TTL); err != nil {
+		return err
+	}
+	m.record(EventStored, msg)
 	return nil
 }
 
-// Receive retrieves messages for a session
-func (m *Messenger) Receive(ctx context.Context, sessionID string) ([]*Message, error) {
-	// TODO: Implement message retrieval from storage nodes
-	return nil, nil
+// SendAsync queues msg for Send on the messenger's worker pool and calls
+// done with the result. Failed sends are retried up to the configured
+// attempts, then moved to DeadLetters. A send dropped by Stop before it
+// started completes with ErrPoolClosed. It blocks only while the work
+// queue is full.
+func (m *Messenger) SendAsync(ctx context.Context, msg *Message, done func(error)) error {
+	return m.workers.Submit(ctx, func() {
+		err := m.deliver(ctx, msg)
+		if done != nil {
+			done(err)
+		}
+	}, done)
+}
+
+// Encode serializes msg in the configured format for storage or
+// transmission; DecodeMessage reads any supported format
+func (m *Messenger) Encode(msg *Message) ([]byte, error) {
+	return EncodeMessage(msg, m.codec)
+}
+
+// MessageSizes returns the distribution of sealed message sizes sent
+func (m *Messenger) MessageSizes() metrics.HistogramSnapshot {
+	return m.sizes.Snapshot()
+}
+
+// PlaintextSizes returns the distribution of plaintext sizes Send sealed
+func (m *Messenger) PlaintextSizes() metrics.HistogramSnapshot {
+	return m.plainSizes.Snapshot()
+}
+
+// RegisterMetrics exports the message size histograms to r
+func (m *Messenger) RegisterMetrics(r *metrics.Registry) error {
+	return errors.Join(
+		r.Register("pars_message_plaintext_bytes", "Plaintext size of messages sealed by Send", m.plainSizes),
+		r.Register("pars_message_sealed_bytes", "Sealed size of messages sent", m.sizes),
+	)
+}
+
+// Saturation reports load on the messaging worker pool; see
+// WorkerPool.Saturation
+func (m *Messenger) Saturation() float64 {
+	return m.workers.Saturation()
+}
+
+// checkTTL enforces the network's configured TTL bounds
+func (m *Messenger) checkTTL(ttl int64) error {
+	bounds := m.cfg.TTL
+	if ttl < bounds.Min || (bounds.Max > 0 && ttl > bounds.Max) {
+		return fmt.Errorf("%w: %ds not within [%d, %d]", ErrInvalidTTL, ttl, bounds.Min, bounds.Max)
+	}
+	return nil
+}
+
+// Admit checks an inbound message before it is stored or delivered,
+// rejecting timestamps more than MaxClockSkew ahead of local time
+func (m *Messenger) Admit(msg *Message) error {
+	if m.cfg.MaxClockSkew == 0 {
+		return nil
+	}
+	skew := time.Duration(m.cfg.MaxClockSkew) * time.Second
+	if ahead := msg.Timestamp.Sub(m.now()); ahead > skew {
+		return fmt.Errorf("%w: message %s is %s ahead (max %s)", ErrClockSkew, msg.ID, ahead.Round(time.Second), skew)
+	}
+	return nil
+}
+
+// Receive retrieves the messages stored for a session, oldest first.
+// Expired messages, messages from unknown senders or failing verification
+// against the sender's contact keys, and messages from senders not on the
+// session's allowlist are dropped. If contentTypes are given, only
+// messages of those types are returned. Open decrypts the results.
+func (m *Messenger) Receive(ctx context.Context, sessionID string, contentTypes ...string) ([]*Message, error) {
+	msgs := []*Message{}
+	if m.storage == nil {
+		return msgs, nil
+	}
+	session, err := canonicalSessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// TODO: Also fetch from remote storage nodes over the onion network
+	stored, err := m.storage.Scan(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages for %s: %w", sessionID, err)
+	}
+
+	now := m.now()
+	for _, data := range stored {
+		msg, err := DecodeMessage(data)
+		if err != nil {
+			continue
+		}
+		if msg.expired(now) || m.Admit(msg) != nil {
+			continue
+		}
+		// Verify before trusting SenderID for the allowlist; without the
+		// sender's keys the message cannot be authenticated at all
+		sender, ok := m.contact(msg.SenderID)
+		if !ok || msg.Verify(sender.DSAPublicKey) != nil {
+			continue
+		}
+		if m.checkSender(sessionID, msg) != nil {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	slices.SortStableFunc(msgs, func(a, b *Message) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	msgs = filterContentType(msgs, contentTypes)
+	for _, msg := range msgs {
+		m.record(EventDelivered, msg)
+	}
+	return msgs, nil
+}
+
+// expired reports whether msg's TTL has elapsed by now
+func (msg *Message) expired(now time.Time) bool {
+	return msg.TTL > 0 && !now.Before(msg.Timestamp.Add(time.Duration(msg.TTL)*time.Second))
+}
+
+// record journals a message event if the journal is enabled
+func (m *Messenger) record(event JournalEvent, msg *Message) {
+	if m.journal == nil {
+		return
+	}
+	// The journal is diagnostic only; a failed write must not fail delivery
+	_ = m.journal.Record(event, msg)
 }
 
-// GenerateIdentity creates a new Pars identity
+// GenerateIdentity creates a new Pars identity whose KEM keypair uses the
+// scheme named by crypto.KEMScheme, or DefaultKEMScheme if it is unset
 // Returns session ID: "07" + hex(Blake2b(KEM_pk || DSA_pk))
-func GenerateIdentity() (*Identity, error) {
-	// TODO: Use lux/crypto for ML-KEM-768 and ML-DSA-65 keygen
-	return nil, nil
+func GenerateIdentity(crypto config.CryptoConfig) (*Identity, error) {
+	kem, err := configuredKEM(crypto)
+	if err != nil {
+		return nil, err
+	}
+	return identityFromReader(rand.Reader, kem)
 }
 
 // Identity represents a Pars network identity
 type Identity struct {
 	SessionID string `json:"sessionId"` // "07" prefix for PQ
 
-	// ML-KEM-768 keypair (for receiving encrypted messages)
+	// ML-KEM keypair (for receiving encrypted messages)
 	KEMPublicKey []byte `json:"kemPublicKey"`
 	KEMSecretKey []byte `json:"kemSecretKey"`
 
@@ -0,0 +1,311 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/metrics"
+	"github.com/parsdao/node/storage"
+)
+
+func TestSendTTLBounds(t *testing.T) {
+	const (
+		mainnet = 7070
+		devnet  = 7072
+	)
+
+	tests := []struct {
+		name    string
+		network uint32
+		ttl     int64
+		valid   bool
+	}{
+		{"mainnet below floor", mainnet, 60, false},
+		{"mainnet within bounds", mainnet, 24 * 60 * 60, true},
+		{"mainnet above ceiling", mainnet, 365 * 24 * 60 * 60, false},
+		{"devnet short ttl", devnet, 60, true},
+		{"devnet zero ttl", devnet, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default().Pars
+			cfg.TTL = config.DefaultTTLBounds(tt.network)
+
+			m, err := NewMessenger(cfg, config.Default().Crypto)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			msg := testMessage()
+			msg.TTL = tt.ttl
+			err = m.Send(context.Background(), msg)
+			if tt.valid && err != nil {
+				t.Errorf("expected TTL %d accepted, got %v", tt.ttl, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidTTL) {
+				t.Errorf("expected ErrInvalidTTL for TTL %d, got %v", tt.ttl, err)
+			}
+		})
+	}
+}
+
+func TestSendRecordsMessageSize(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, size := range []int{100, 1000, 1000, 2_000_000} {
+		msg := testMessage()
+		msg.Ciphertext = make([]byte, size)
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	got := m.MessageSizes()
+	want := map[int]uint64{0: 1, 1: 2, len(got.Counts) - 1: 1} // <=256, <=1KB, overflow
+	for i, c := range got.Counts {
+		if c != want[i] {
+			t.Errorf("bucket %d: expected %d, got %d", i, want[i], c)
+		}
+	}
+	if n := m.PlaintextSizes().Count(); n != 0 {
+		t.Errorf("expected no plaintext sizes for pre-sealed messages, got %d", n)
+	}
+
+	// Plaintext is recorded before sealing, the sealed size after
+	msg := loopback(t, m)
+	msg.Plaintext = make([]byte, 3000)
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if plain := m.PlaintextSizes(); plain.Count() != 1 || plain.Counts[2] != 1 || plain.Sum != 3000 {
+		t.Errorf("expected one plaintext of 3000 bytes in the <=4KB bucket, got %+v", plain)
+	}
+	if sealed := m.MessageSizes(); sealed.Count() != 5 || sealed.Sum == got.Sum {
+		t.Errorf("expected the sealed size recorded, got %+v", sealed)
+	}
+
+	r := metrics.NewRegistry()
+	if err := m.RegisterMetrics(r); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	var out strings.Builder
+	if err := r.WritePrometheus(&out); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, line := range []string{
+		`pars_message_plaintext_bytes_bucket{le="4096"} 1`,
+		"pars_message_sealed_bytes_count 5",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestAdmitClockSkew(t *testing.T) {
+	cfg := config.Default().Pars
+	cfg.MaxClockSkew = 5 * 60
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	m.now = func() time.Time { return now }
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		valid  bool
+	}{
+		{"past", -time.Hour, true},
+		{"within tolerance", time.Minute, true},
+		{"an hour ahead", time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		msg := testMessage()
+		msg.Timestamp = now.Add(tt.offset)
+		err := m.Admit(msg)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrClockSkew) {
+			t.Errorf("%s: expected ErrClockSkew, got %v", tt.name, err)
+		}
+	}
+
+	m.cfg.MaxClockSkew = 0
+	msg := testMessage()
+	msg.Timestamp = now.Add(time.Hour)
+	if err := m.Admit(msg); err != nil {
+		t.Errorf("expected no check when disabled, got %v", err)
+	}
+}
+
+func TestReceiveEmpty(t *testing.T) {
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+
+	msgs, err := m.Receive(context.Background(), testRecipient)
+	if err != nil || msgs == nil || len(msgs) != 0 {
+		t.Errorf("without storage: expected empty slice, got %v, %v", msgs, err)
+	}
+
+	node, err := storage.NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.SetStorage(node)
+	msgs, err = m.Receive(context.Background(), testRecipient)
+	if err != nil || msgs == nil || len(msgs) != 0 {
+		t.Errorf("with storage: expected empty slice, got %v, %v", msgs, err)
+	}
+}
+
+func TestReceiveOrderAndExpiry(t *testing.T) {
+	node, err := storage.NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+	m.SetStorage(node)
+	now := time.Unix(1700000000, 0).UTC()
+	m.now = func() time.Time { return now }
+	sender, signer := testContact(t, m, 0x53)
+
+	for _, tt := range []struct {
+		id  string
+		age time.Duration
+	}{
+		{"newest", time.Minute},
+		{"expired", 2 * time.Hour},
+		{"oldest", 30 * time.Minute},
+		{"middle", 10 * time.Minute},
+	} {
+		msg := testMessage()
+		msg.ID = tt.id
+		msg.SenderID = sender
+		msg.Timestamp = now.Add(-tt.age)
+		if err := msg.Sign(signer); err != nil {
+			t.Fatalf("sign %s: %v", tt.id, err)
+		}
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatalf("send %s: %v", tt.id, err)
+		}
+	}
+	other := testMessage()
+	other.ID = "other"
+	other.SenderID = sender
+	other.RecipientID = testSender
+	if err := other.Sign(signer); err != nil {
+		t.Fatalf("sign other: %v", err)
+	}
+	if err := m.Send(context.Background(), other); err != nil {
+		t.Fatalf("send other: %v", err)
+	}
+
+	msgs, err := m.Receive(context.Background(), testRecipient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, msg := range msgs {
+		got = append(got, msg.ID)
+	}
+	if want := []string{"oldest", "middle", "newest"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// testContact adds a seeded identity to m's contacts, returning its
+// session ID and signer
+func testContact(t *testing.T, m *Messenger, seed byte) (string, Signer) {
+	t.Helper()
+	id, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{seed}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.AddContact(id.SessionID, id.KEMPublicKey, id.DSAPublicKey); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	signer, err := NewSoftwareSigner(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return id.SessionID, signer
+}
+
+func TestReceiveDropsUnauthenticatedSenders(t *testing.T) {
+	node, err := storage.NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+	m.SetStorage(node)
+	m.now = func() time.Time { return testMessage().Timestamp }
+
+	sender, signer := testContact(t, m, 0x53)
+	if err := m.AllowSenders(testRecipient, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An unknown key forging the allowlisted sender's ID
+	forger, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{0x46}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	forgerSigner, err := NewSoftwareSigner(forger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stranger := PQPrefix + strings.Repeat("ef", 32)
+
+	for _, tt := range []struct {
+		id     string
+		sender string
+		signer Signer
+	}{
+		{"genuine", sender, signer},
+		{"forged", sender, forgerSigner},
+		{"unknown", stranger, forgerSigner},
+	} {
+		msg := testMessage()
+		msg.ID = tt.id
+		msg.SenderID = tt.sender
+		if err := msg.Sign(tt.signer); err != nil {
+			t.Fatalf("sign %s: %v", tt.id, err)
+		}
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatalf("send %s: %v", tt.id, err)
+		}
+	}
+
+	msgs, err := m.Receive(context.Background(), testRecipient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != "genuine" {
+		t.Errorf("expected only the genuine message, got %d messages", len(msgs))
+	}
+}
@@ -0,0 +1,57 @@
+package messaging
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// lengthPrefixSize is the size of the plaintext length field
+	lengthPrefixSize = 4
+
+	// minPadBucket is the smallest padded plaintext size
+	minPadBucket = 256
+)
+
+var ErrInvalidPadding = errors.New("invalid message padding")
+
+// padPlaintext frames plaintext with its length and, when maxBucket is
+// positive, zero-pads it to the next power-of-two bucket. Sizes above
+// maxBucket are rounded up to a multiple of maxBucket. The length field
+// sits inside the plaintext so it is covered by the AEAD tag.
+func padPlaintext(plaintext []byte, maxBucket int) []byte {
+	size := lengthPrefixSize + len(plaintext)
+	if maxBucket > 0 {
+		size = bucketSize(size, maxBucket)
+	}
+
+	out := make([]byte, size)
+	binary.BigEndian.PutUint32(out, uint32(len(plaintext)))
+	copy(out[lengthPrefixSize:], plaintext)
+	return out
+}
+
+// unpadPlaintext strips the framing added by padPlaintext
+func unpadPlaintext(padded []byte) ([]byte, error) {
+	if len(padded) < lengthPrefixSize {
+		return nil, ErrInvalidPadding
+	}
+
+	n := binary.BigEndian.Uint32(padded)
+	if uint64(n) > uint64(len(padded)-lengthPrefixSize) {
+		return nil, ErrInvalidPadding
+	}
+	return padded[lengthPrefixSize : lengthPrefixSize+int(n)], nil
+}
+
+// bucketSize returns the padded size for n bytes
+func bucketSize(n, maxBucket int) int {
+	bucket := minPadBucket
+	for bucket < n && bucket < maxBucket {
+		bucket *= 2
+	}
+	if n <= bucket {
+		return bucket
+	}
+	return (n + maxBucket - 1) / maxBucket * maxBucket
+}
@@ -0,0 +1,71 @@
+package messaging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPaddingBuckets(t *testing.T) {
+	const maxBucket = 4096
+
+	tests := []struct {
+		size   int
+		padded int
+	}{
+		{0, 256},
+		{1, 256},
+		{252, 256},
+		{253, 512},
+		{1000, 1024},
+		{4092, 4096},
+		{4093, 8192},  // Above the cap: multiples of maxBucket
+		{9000, 12288}, // 9004 rounds up to 3 * 4096
+	}
+
+	for _, tt := range tests {
+		plaintext := bytes.Repeat([]byte{'x'}, tt.size)
+
+		padded := padPlaintext(plaintext, maxBucket)
+		if len(padded) != tt.padded {
+			t.Errorf("size %d: expected padded length %d, got %d", tt.size, tt.padded, len(padded))
+		}
+
+		got, err := unpadPlaintext(padded)
+		if err != nil {
+			t.Fatalf("size %d: unpad: %v", tt.size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("size %d: round trip mismatch", tt.size)
+		}
+	}
+}
+
+func TestPaddingDisabled(t *testing.T) {
+	plaintext := []byte("hello")
+
+	padded := padPlaintext(plaintext, 0)
+	if len(padded) != lengthPrefixSize+len(plaintext) {
+		t.Errorf("expected unpadded framing, got %d bytes", len(padded))
+	}
+
+	got, err := unpadPlaintext(padded)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("round trip failed: %q, %v", got, err)
+	}
+}
+
+func TestUnpadInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{0, 0, 1},
+		{0, 0, 0, 9, 'a', 'b'}, // Length exceeds payload
+		{0xff, 0xff, 0xff, 0xff},
+	}
+
+	for _, data := range tests {
+		if _, err := unpadPlaintext(data); !errors.Is(err, ErrInvalidPadding) {
+			t.Errorf("%x: expected ErrInvalidPadding, got %v", data, err)
+		}
+	}
+}
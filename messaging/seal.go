@@ -0,0 +1,177 @@
+package messaging
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/luxfi/crypto/blake2b"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// keyDomain separates message keys from any other use of a KEM secret
+const keyDomain = "pars-message-key-v1"
+
+var (
+	ErrUnknownRecipient = errors.New("recipient public key unknown")
+	ErrUnknownSender    = errors.New("sender public key unknown")
+)
+
+// Contact holds the public keys behind a session ID
+type Contact struct {
+	KEMPublicKey []byte
+	DSAPublicKey []byte
+}
+
+// contactBook holds contacts by canonical hex session ID
+type contactBook struct {
+	mu       sync.RWMutex
+	contacts map[string]Contact
+}
+
+// AddContact records the public keys for sessionID, which must be derived
+// from them, so Send can encrypt to it and Open can verify it
+func (m *Messenger) AddContact(sessionID string, kemPublicKey, dsaPublicKey []byte) error {
+	session, err := canonicalSessionID(sessionID)
+	if err != nil {
+		return err
+	}
+	derived, err := DeriveSessionID(kemPublicKey, dsaPublicKey, SessionIDHex)
+	if err != nil {
+		return err
+	}
+	if derived != session {
+		return fmt.Errorf("%w: %s does not match its public keys", ErrInvalidSessionID, sessionID)
+	}
+
+	b := &m.contacts
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.contacts == nil {
+		b.contacts = make(map[string]Contact)
+	}
+	b.contacts[session] = Contact{KEMPublicKey: kemPublicKey, DSAPublicKey: dsaPublicKey}
+	return nil
+}
+
+// contact returns the public keys recorded for sessionID
+func (m *Messenger) contact(sessionID string) (Contact, bool) {
+	session, err := canonicalSessionID(sessionID)
+	if err != nil {
+		return Contact{}, false
+	}
+	b := &m.contacts
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	c, ok := b.contacts[session]
+	return c, ok
+}
+
+// seal sets msg.Ciphertext from msg.Plaintext: compress and pad, then
+// encapsulate to the recipient's KEM key, with the scheme of that key,
+// and encrypt with
+// XChaCha20-Poly1305 under a key derived from the shared secret. The
+// ciphertext is the length-prefixed KEM ciphertext, nonce and sealed box.
+func (m *Messenger) seal(msg *Message) error {
+	if err := ValidateSessionID(msg.RecipientID); err != nil {
+		return err
+	}
+	recipient, ok := m.contact(msg.RecipientID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownRecipient, msg.RecipientID)
+	}
+
+	compressed, err := m.compressor.Compress(msg.Plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to compress message: %w", err)
+	}
+	maxBucket := 0
+	if m.cfg.Padding.Enabled {
+		maxBucket = m.cfg.Padding.MaxBucket
+	}
+	padded := padPlaintext(compressed, maxBucket)
+
+	kem, err := kemForPublicKey(recipient.KEMPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid KEM key for %s: %w", msg.RecipientID, err)
+	}
+	kemCT, secret, err := kem.Encapsulate(recipient.KEMPublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to encapsulate to %s: %w", msg.RecipientID, err)
+	}
+	aead, err := chacha20poly1305.NewX(messageKey(secret))
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(kemCT)))
+	out = append(out, kemCT...)
+	out = append(out, nonce...)
+	msg.Ciphertext = aead.Seal(out, nonce, padded, nil)
+	msg.KEMScheme = kem.Name()
+	msg.Compression = m.compressor.Name()
+	return nil
+}
+
+// Open verifies msg against its sender's contact keys and decrypts it
+// with id's KEM secret key, returning the plaintext
+func (m *Messenger) Open(msg *Message, id *Identity) ([]byte, error) {
+	sender, ok := m.contact(msg.SenderID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSender, msg.SenderID)
+	}
+	if err := msg.Verify(sender.DSAPublicKey); err != nil {
+		return nil, err
+	}
+
+	kem, err := LookupKEM(msg.KEMScheme)
+	if err != nil {
+		return nil, err
+	}
+	compressor, err := LookupCompressor(msg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	ct := msg.Ciphertext
+	if len(ct) < 2 {
+		return nil, fmt.Errorf("%w: short ciphertext", ErrInvalidMessage)
+	}
+	n := int(binary.BigEndian.Uint16(ct))
+	ct = ct[2:]
+	if len(ct) < n+chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("%w: short ciphertext", ErrInvalidMessage)
+	}
+	secret, err := kem.Decapsulate(id.KEMSecretKey, ct[:n])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decapsulate: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(messageKey(secret))
+	if err != nil {
+		return nil, err
+	}
+	nonce, box := ct[n:n+chacha20poly1305.NonceSizeX], ct[n+chacha20poly1305.NonceSizeX:]
+	padded, err := aead.Open(nil, nonce, box, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decryption failed", ErrInvalidMessage)
+	}
+
+	compressed, err := unpadPlaintext(padded)
+	if err != nil {
+		return nil, err
+	}
+	return compressor.Decompress(compressed)
+}
+
+// messageKey derives the symmetric message key from a KEM shared secret
+func messageKey(secret []byte) []byte {
+	h, _ := blake2b.New256(secret)
+	h.Write([]byte(keyDomain))
+	return h.Sum(nil)
+}
@@ -0,0 +1,161 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/storage"
+)
+
+// testPeer returns a messenger for a seeded identity, signing with its key
+func testPeer(t *testing.T, seed byte, node *storage.Node) (*Messenger, *Identity) {
+	t.Helper()
+	id, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{seed}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(m.Stop)
+	signer, err := NewSoftwareSigner(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.SetSigner(signer)
+	m.SetStorage(node)
+	return m, id
+}
+
+// loopback gives m a seeded identity as both its signing key and a
+// contact, returning an unsealed message from that identity to itself
+func loopback(t *testing.T, m *Messenger) *Message {
+	t.Helper()
+	id, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{0x4c}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signer, err := NewSoftwareSigner(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.SetSigner(signer)
+	if err := m.AddContact(id.SessionID, id.KEMPublicKey, id.DSAPublicKey); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	return &Message{ID: "loop", SenderID: id.SessionID, RecipientID: id.SessionID, Plaintext: []byte("hello"), TTL: 3600}
+}
+
+func TestSendReceiveRoundTrip(t *testing.T) {
+	node, err := storage.NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alice, aliceID := testPeer(t, 0x41, node)
+	bob, bobID := testPeer(t, 0x42, node)
+	if err := alice.AddContact(bobID.SessionID, bobID.KEMPublicKey, bobID.DSAPublicKey); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	if err := bob.AddContact(aliceID.SessionID, aliceID.KEMPublicKey, aliceID.DSAPublicKey); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+
+	plaintext := []byte("hello bob")
+	msg := &Message{ID: "m1", SenderID: aliceID.SessionID, RecipientID: bobID.SessionID, Plaintext: plaintext, TTL: 3600}
+	if err := alice.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(msg.Ciphertext) == 0 || len(msg.Signature) == 0 {
+		t.Fatal("expected ciphertext and signature populated")
+	}
+	if bytes.Contains(msg.Ciphertext, plaintext) {
+		t.Error("ciphertext contains the plaintext")
+	}
+
+	msgs, err := bob.Receive(context.Background(), bobID.SessionID)
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	stored := msgs[0]
+	got, err := bob.Open(stored, bobID)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, got)
+	}
+
+	if _, err := bob.Open(stored, aliceID); err == nil {
+		t.Error("expected another identity unable to open the message")
+	}
+	stored.Ciphertext[len(stored.Ciphertext)-1] ^= 1
+	if _, err := bob.Open(stored, bobID); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for tampered ciphertext, got %v", err)
+	}
+}
+
+func TestSendRecipientErrors(t *testing.T) {
+	m, id := testPeer(t, 0x41, nil)
+
+	tests := []struct {
+		recipient string
+		want      error
+	}{
+		{"07zz", ErrInvalidSessionID},
+		{testRecipient, ErrUnknownRecipient},
+	}
+	for _, tt := range tests {
+		msg := &Message{ID: "m1", SenderID: id.SessionID, RecipientID: tt.recipient, Plaintext: []byte("hi"), TTL: 3600}
+		if err := m.Send(context.Background(), msg); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.recipient, tt.want, err)
+		}
+	}
+}
+
+func TestAddContactMismatch(t *testing.T) {
+	m, id := testPeer(t, 0x41, nil)
+	if err := m.AddContact(testRecipient, id.KEMPublicKey, id.DSAPublicKey); !errors.Is(err, ErrInvalidSessionID) {
+		t.Errorf("expected ErrInvalidSessionID, got %v", err)
+	}
+}
+
+func TestSendSignFailureLeavesMessageUnsealed(t *testing.T) {
+	node, err := storage.NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(m.Stop)
+	m.SetStorage(node)
+	msg := loopback(t, m)
+	m.SetSigner(nil)
+
+	// Retries must hit the same error rather than store an unsigned message
+	for range 2 {
+		if err := m.Send(context.Background(), msg); !errors.Is(err, ErrNoSigner) {
+			t.Fatalf("expected ErrNoSigner, got %v", err)
+		}
+		if len(msg.Ciphertext) != 0 || msg.KEMScheme != "" {
+			t.Fatal("expected message untouched by a failed send")
+		}
+	}
+	if stored, err := node.Scan(context.Background(), msg.RecipientID); err != nil || len(stored) != 0 {
+		t.Errorf("expected nothing stored, got %d, %v", len(stored), err)
+	}
+
+	unsigned := testMessage()
+	unsigned.Signature = nil
+	if err := m.Send(context.Background(), unsigned); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for an unsigned sealed message, got %v", err)
+	}
+}
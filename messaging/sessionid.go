@@ -0,0 +1,84 @@
+package messaging
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/luxfi/crypto/blake2b"
+)
+
+// Session ID encodings, selected by config.SessionConfig.IDEncoding
+const (
+	// SessionIDHex is the prefix followed by the hex digest, e.g. "07ab..."
+	SessionIDHex = "hex"
+
+	// SessionIDBech32 is bech32 over the prefix byte and digest, e.g. "pars1..."
+	SessionIDBech32 = "bech32"
+)
+
+// SessionIDHRP is the bech32 human-readable part of session IDs
+const SessionIDHRP = "pars"
+
+// sessionDigestLen is the Blake2b-256 digest length
+const sessionDigestLen = 32
+
+// DeriveSessionID returns the PQ session ID for the given public keys,
+// Blake2b-256(KEM_pk || DSA_pk) under the "07" prefix, in encoding
+func DeriveSessionID(kemPublicKey, dsaPublicKey []byte, encoding string) (string, error) {
+	h, _ := blake2b.New256(nil)
+	h.Write(kemPublicKey)
+	h.Write(dsaPublicKey)
+
+	raw, _ := hex.DecodeString(PQPrefix)
+	return FormatSessionID(h.Sum(raw), encoding)
+}
+
+// FormatSessionID encodes a raw session ID, the prefix byte followed by
+// the digest, in encoding
+func FormatSessionID(raw []byte, encoding string) (string, error) {
+	if len(raw) != 1+sessionDigestLen {
+		return "", fmt.Errorf("%w: raw length %d", ErrInvalidSessionID, len(raw))
+	}
+	switch encoding {
+	case SessionIDHex:
+		return hex.EncodeToString(raw), nil
+	case SessionIDBech32:
+		return bech32Encode(SessionIDHRP, raw), nil
+	default:
+		return "", fmt.Errorf("%w: unknown encoding %q", ErrInvalidSessionID, encoding)
+	}
+}
+
+// ParseSessionID decodes a session ID in either encoding, detected from
+// the bech32 HRP, and returns the prefix byte followed by the digest
+func ParseSessionID(id string) ([]byte, error) {
+	var raw []byte
+	if strings.HasPrefix(strings.ToLower(id), SessionIDHRP+"1") {
+		hrp, data, err := bech32Decode(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSessionID, err)
+		}
+		if hrp != SessionIDHRP {
+			return nil, fmt.Errorf("%w: unknown HRP %q", ErrInvalidSessionID, hrp)
+		}
+		raw = data
+	} else {
+		if len(id) != sessionIDLen {
+			return nil, fmt.Errorf("%w: length %d", ErrInvalidSessionID, len(id))
+		}
+		data, err := hex.DecodeString(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSessionID, err)
+		}
+		raw = data
+	}
+
+	if len(raw) != 1+sessionDigestLen {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidSessionID, len(raw))
+	}
+	if prefix := hex.EncodeToString(raw[:1]); prefix != PQPrefix && prefix != LegacyPrefix {
+		return nil, fmt.Errorf("%w: unknown prefix %q", ErrInvalidSessionID, prefix)
+	}
+	return raw, nil
+}
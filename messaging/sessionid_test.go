@@ -0,0 +1,96 @@
+package messaging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestSessionIDEncodings(t *testing.T) {
+	id, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{0x42}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hexID, err := DeriveSessionID(id.KEMPublicKey, id.DSAPublicKey, SessionIDHex)
+	if err != nil {
+		t.Fatalf("hex: unexpected error: %v", err)
+	}
+	if hexID != id.SessionID {
+		t.Errorf("expected %s, got %s", id.SessionID, hexID)
+	}
+	bechID, err := DeriveSessionID(id.KEMPublicKey, id.DSAPublicKey, SessionIDBech32)
+	if err != nil {
+		t.Fatalf("bech32: unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(bechID, "pars1") {
+		t.Errorf("expected pars1 prefix, got %s", bechID)
+	}
+
+	fromHex, err := ParseSessionID(hexID)
+	if err != nil {
+		t.Fatalf("parse hex: %v", err)
+	}
+	fromBech, err := ParseSessionID(bechID)
+	if err != nil {
+		t.Fatalf("parse bech32: %v", err)
+	}
+	if !bytes.Equal(fromHex, fromBech) {
+		t.Errorf("encodings decode differently: %x != %x", fromHex, fromBech)
+	}
+	for encoding, want := range map[string]string{SessionIDHex: hexID, SessionIDBech32: bechID} {
+		if got, err := FormatSessionID(fromHex, encoding); err != nil || got != want {
+			t.Errorf("%s: expected %s, got %s, %v", encoding, want, got, err)
+		}
+	}
+	if err := ValidateSessionID(strings.ToUpper(bechID)); err != nil {
+		t.Errorf("expected upper-case bech32 accepted, got %v", err)
+	}
+
+	cfg := config.Default().Pars
+	cfg.Session.IDEncoding = SessionIDBech32
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := m.SessionID(id); err != nil || got != bechID {
+		t.Errorf("expected configured encoding %s, got %s, %v", bechID, got, err)
+	}
+}
+
+func TestParseSessionIDInvalid(t *testing.T) {
+	valid, err := FormatSessionID(append([]byte{0x07}, make([]byte, 32)...), SessionIDBech32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wrongPrefix := bech32Encode(SessionIDHRP, append([]byte{0x06}, make([]byte, 32)...))
+	short := bech32Encode(SessionIDHRP, append([]byte{0x07}, make([]byte, 31)...))
+
+	for _, id := range []string{
+		valid[:len(valid)-1] + "q", // Bad checksum
+		strings.ToUpper(valid[:6]) + valid[6:],
+		wrongPrefix,
+		short,
+		"pars1",
+	} {
+		if _, err := ParseSessionID(id); !errors.Is(err, ErrInvalidSessionID) {
+			t.Errorf("%q: expected ErrInvalidSessionID, got %v", id, err)
+		}
+	}
+}
+
+func TestBech32Vectors(t *testing.T) {
+	// Valid checksums from BIP-173
+	for _, s := range []string{
+		"A12UEL5L",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+	} {
+		if _, _, err := bech32Decode(s); err != nil {
+			t.Errorf("%s: unexpected error: %v", s, err)
+		}
+	}
+}
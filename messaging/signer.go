@@ -0,0 +1,42 @@
+package messaging
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/crypto/mldsa"
+)
+
+var ErrNoSigner = errors.New("no signer configured")
+
+// Signer produces ML-DSA-65 signatures without exposing its private key,
+// so keys held outside the process can sign messages too
+type Signer interface {
+	// PublicKey returns the ML-DSA-65 public key that verifies signatures
+	PublicKey() []byte
+
+	Sign(data []byte) ([]byte, error)
+}
+
+// softwareSigner signs with an in-memory ML-DSA-65 key
+type softwareSigner struct {
+	key *mldsa.PrivateKey
+}
+
+// NewSoftwareSigner returns a Signer over id's DSA secret key
+func NewSoftwareSigner(id *Identity) (Signer, error) {
+	key, err := mldsa.PrivateKeyFromBytes(mldsa.MLDSA65, id.DSASecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	return softwareSigner{key: key}, nil
+}
+
+func (s softwareSigner) PublicKey() []byte {
+	return s.key.PublicKey.Bytes()
+}
+
+func (s softwareSigner) Sign(data []byte) ([]byte, error) {
+	return s.key.Sign(rand.Reader, data, nil)
+}
@@ -0,0 +1,69 @@
+package messaging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+// externalSigner stands in for a key held outside the process, signing
+// with a software key it never hands out
+type externalSigner struct {
+	key   Signer
+	signs int
+}
+
+func (s *externalSigner) PublicKey() []byte {
+	return s.key.PublicKey()
+}
+
+func (s *externalSigner) Sign(data []byte) ([]byte, error) {
+	s.signs++
+	return s.key.Sign(data)
+}
+
+func TestSigners(t *testing.T) {
+	id, err := GenerateIdentityFromSeed(bytes.Repeat([]byte{0x42}, MinSeedSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	software, err := NewSoftwareSigner(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	external := &externalSigner{key: software}
+
+	m, err := NewMessenger(config.Default().Pars, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.SignMessage(testMessage()); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("expected ErrNoSigner, got %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		signer Signer
+	}{
+		{"software", software},
+		{"external", external},
+	} {
+		if !bytes.Equal(tt.signer.PublicKey(), id.DSAPublicKey) {
+			t.Errorf("%s: unexpected public key", tt.name)
+		}
+
+		m.SetSigner(tt.signer)
+		msg := testMessage()
+		if err := m.SignMessage(msg); err != nil {
+			t.Fatalf("%s: sign: %v", tt.name, err)
+		}
+		if err := msg.Verify(id.DSAPublicKey); err != nil {
+			t.Errorf("%s: verify: %v", tt.name, err)
+		}
+	}
+	if external.signs != 1 {
+		t.Errorf("expected one external signature, got %d", external.signs)
+	}
+}
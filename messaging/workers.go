@@ -0,0 +1,178 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultMaxWorkers is used when the config leaves MaxWorkers unset
+	DefaultMaxWorkers = 64
+
+	// workQueueSize is how much work may wait for a free worker before
+	// Submit blocks
+	workQueueSize = 1024
+)
+
+var ErrPoolClosed = errors.New("worker pool closed")
+
+// WorkerPool runs messaging work on at most max goroutines. Workers are
+// started on demand; once max are running, further work queues for the
+// next free worker instead of spawning more.
+type WorkerPool struct {
+	max   int
+	tasks chan task
+	quit  chan struct{}
+	busy  atomic.Int64
+
+	// submitting is read-held by Submit while it queues, so stop can wait
+	// for in-flight submits before dropping the queue
+	submitting sync.RWMutex
+
+	mu       sync.Mutex
+	workers  int
+	closed   bool
+	wg       sync.WaitGroup
+	pending  sync.WaitGroup // Submitted tasks not yet finished or dropped
+	quitOnce sync.Once
+}
+
+// task is queued work. drop, if set, is called with ErrPoolClosed instead
+// of run when the pool closes before the task starts.
+type task struct {
+	run  func()
+	drop func(error)
+}
+
+// NewWorkerPool creates a pool of at most max workers
+func NewWorkerPool(max int) *WorkerPool {
+	if max <= 0 {
+		max = DefaultMaxWorkers
+	}
+	return &WorkerPool{
+		max:   max,
+		tasks: make(chan task, workQueueSize),
+		quit:  make(chan struct{}),
+	}
+}
+
+// Submit queues run for a worker. It blocks while the queue is full,
+// until ctx is done or the pool is closed. If the pool closes before run
+// starts, drop is called with ErrPoolClosed instead; drop may be nil.
+func (p *WorkerPool) Submit(ctx context.Context, run func(), drop func(error)) error {
+	p.submitting.RLock()
+	defer p.submitting.RUnlock()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+	if p.workers < p.max && int(p.busy.Load())+len(p.tasks) >= p.workers {
+		p.workers++
+		p.wg.Add(1)
+		go p.work()
+	}
+	p.pending.Add(1)
+	p.mu.Unlock()
+
+	select {
+	case p.tasks <- task{run: run, drop: drop}:
+		return nil
+	case <-p.quit:
+		p.pending.Done()
+		return ErrPoolClosed
+	case <-ctx.Done():
+		p.pending.Done()
+		return ctx.Err()
+	}
+}
+
+// Saturation returns running plus queued work as a fraction of max
+// workers. Above 1 work is waiting for a free worker.
+func (p *WorkerPool) Saturation() float64 {
+	return float64(int(p.busy.Load())+len(p.tasks)) / float64(p.max)
+}
+
+// Drain stops accepting work and waits for queued and running tasks to
+// finish before stopping the workers. If ctx is done first, the rest of
+// the queue is dropped as by Close and ctx.Err() is returned.
+func (p *WorkerPool) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.pending.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-p.quit:
+		// Already closed; nothing left will run
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	p.stop()
+	return err
+}
+
+// Close stops the workers after their current task. Queued work that has
+// not started is dropped, calling its drop function.
+func (p *WorkerPool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	p.stop()
+}
+
+func (p *WorkerPool) stop() {
+	p.quitOnce.Do(func() { close(p.quit) })
+	p.wg.Wait()
+
+	// Account for dropped work so a timed out Drain's waiter can exit,
+	// reporting it once no Submit is queueing
+	var dropped []task
+	p.submitting.Lock()
+	for len(p.tasks) > 0 {
+		dropped = append(dropped, <-p.tasks)
+		p.pending.Done()
+	}
+	p.submitting.Unlock()
+	for _, t := range dropped {
+		if t.drop != nil {
+			t.drop(ErrPoolClosed)
+		}
+	}
+}
+
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.quit:
+			return
+		case t := <-p.tasks:
+			// Don't start queued work once the pool is stopping
+			select {
+			case <-p.quit:
+				if t.drop != nil {
+					t.drop(ErrPoolClosed)
+				}
+				p.pending.Done()
+				return
+			default:
+			}
+			p.busy.Add(1)
+			t.run()
+			p.busy.Add(-1)
+			p.pending.Done()
+		}
+	}
+}
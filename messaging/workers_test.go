@@ -0,0 +1,174 @@
+package messaging
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestWorkerPoolBounded(t *testing.T) {
+	const max, tasks = 4, 100
+
+	p := NewWorkerPool(max)
+	defer p.Close()
+
+	base := runtime.NumGoroutine()
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(tasks)
+	for range tasks {
+		if err := p.Submit(context.Background(), func() {
+			<-release
+			wg.Done()
+		}, nil); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	if n := runtime.NumGoroutine(); n > base+max {
+		t.Errorf("expected at most %d new goroutines, got %d", max, n-base)
+	}
+	if s := p.Saturation(); s <= 1 {
+		t.Errorf("expected saturation above 1 with queued work, got %v", s)
+	}
+
+	close(release)
+	wg.Wait()
+}
+
+func TestWorkerPoolClosed(t *testing.T) {
+	p := NewWorkerPool(1)
+	p.Close()
+	if err := p.Submit(context.Background(), func() {}, nil); err != ErrPoolClosed {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestWorkerPoolCloseDropsQueued(t *testing.T) {
+	p := NewWorkerPool(1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	if err := p.Submit(context.Background(), func() {
+		close(started)
+		<-release
+	}, nil); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+
+	var ran, dropped atomic.Int64
+	for range 10 {
+		err := p.Submit(context.Background(), func() { ran.Add(1) }, func(err error) {
+			if err != ErrPoolClosed {
+				t.Errorf("expected ErrPoolClosed, got %v", err)
+			}
+			dropped.Add(1)
+		})
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	p.Close()
+	if ran.Load() != 0 || dropped.Load() != 10 {
+		t.Errorf("expected all 10 queued tasks dropped, got %d run and %d dropped", ran.Load(), dropped.Load())
+	}
+}
+
+func TestSendAsyncFlood(t *testing.T) {
+	const sends = 1000
+
+	cfg := config.Default().Pars
+	cfg.MaxWorkers = 8
+	m, err := NewMessenger(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Stop()
+
+	base := runtime.NumGoroutine()
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		peak int
+	)
+	wg.Add(sends)
+	for range sends {
+		err := m.SendAsync(context.Background(), testMessage(), func(err error) {
+			if err != nil {
+				t.Errorf("send: %v", err)
+			}
+			mu.Lock()
+			peak = max(peak, runtime.NumGoroutine())
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			wg.Done()
+		})
+		if err != nil {
+			t.Fatalf("send async: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if peak > base+cfg.MaxWorkers {
+		t.Errorf("expected at most %d new goroutines, peaked at %d", cfg.MaxWorkers, peak-base)
+	}
+}
+
+func TestWorkerPoolDrain(t *testing.T) {
+	p := NewWorkerPool(1)
+	var ran atomic.Int64
+	for range 100 {
+		err := p.Submit(context.Background(), func() {
+			time.Sleep(100 * time.Microsecond)
+			ran.Add(1)
+		}, nil)
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ran.Load(); got != 100 {
+		t.Errorf("expected all 100 queued tasks run, got %d", got)
+	}
+	if err := p.Submit(context.Background(), func() {}, nil); err != ErrPoolClosed {
+		t.Errorf("expected ErrPoolClosed after drain, got %v", err)
+	}
+}
+
+func TestWorkerPoolDrainTimeout(t *testing.T) {
+	p := NewWorkerPool(1)
+	release := make(chan struct{})
+	for range 2 {
+		if err := p.Submit(context.Background(), func() { <-release }, nil); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- p.Drain(ctx) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("drain returned %v before the running task finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-done; err != context.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Package metrics provides lock-free counters shared by the node subsystems
+package metrics
+
+import "sync/atomic"
+
+// SizeBuckets are the upper bounds in bytes of the message size buckets,
+// spanning a short text message to a large attachment
+var SizeBuckets = []uint64{256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// Histogram counts observations into non-cumulative buckets by upper
+// bound. Values above the last bound land in a final overflow bucket.
+type Histogram struct {
+	bounds []uint64
+	counts []atomic.Uint64 // len(bounds)+1, the last is overflow
+	sum    atomic.Uint64
+}
+
+// NewHistogram creates a histogram with the given ascending upper bounds
+func NewHistogram(bounds []uint64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+// NewSizeHistogram creates a histogram over SizeBuckets
+func NewSizeHistogram() *Histogram {
+	return NewHistogram(SizeBuckets)
+}
+
+// Observe records one value
+func (h *Histogram) Observe(v uint64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(v)
+}
+
+// HistogramSnapshot is a point-in-time copy of a Histogram
+type HistogramSnapshot struct {
+	Bounds []uint64 // Upper bound of each bucket but the last
+	Counts []uint64 // Observations per bucket, overflow last
+	Sum    uint64   // Total of all observed values
+}
+
+// Snapshot returns the current bucket counts
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	counts := make([]uint64, len(h.counts))
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+	}
+	return HistogramSnapshot{Bounds: h.bounds, Counts: counts, Sum: h.sum.Load()}
+}
+
+// Count returns the total number of observations
+func (s HistogramSnapshot) Count() uint64 {
+	var n uint64
+	for _, c := range s.Counts {
+		n += c
+	}
+	return n
+}
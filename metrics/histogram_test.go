@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	h := NewHistogram([]uint64{10, 100})
+	for _, v := range []uint64{0, 10, 11, 100, 101, 5000} {
+		h.Observe(v)
+	}
+
+	s := h.Snapshot()
+	if want := []uint64{2, 2, 2}; !slices.Equal(s.Counts, want) {
+		t.Errorf("expected counts %v, got %v", want, s.Counts)
+	}
+	if s.Count() != 6 {
+		t.Errorf("expected 6 observations, got %d", s.Count())
+	}
+	if s.Sum != 5222 {
+		t.Errorf("expected sum 5222, got %d", s.Sum)
+	}
+}
+
+func TestRegistryWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	h := NewHistogram([]uint64{10, 100})
+	if err := r.Register("pars_test_bytes", "Test sizes", h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register("pars_test_bytes", "Again", h); !errors.Is(err, ErrDuplicateMetric) {
+		t.Errorf("expected ErrDuplicateMetric, got %v", err)
+	}
+	for _, v := range []uint64{5, 50, 500} {
+		h.Observe(v)
+	}
+
+	var out strings.Builder
+	if err := r.WritePrometheus(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# HELP pars_test_bytes Test sizes
+# TYPE pars_test_bytes histogram
+pars_test_bytes_bucket{le="10"} 1
+pars_test_bytes_bucket{le="100"} 2
+pars_test_bytes_bucket{le="+Inf"} 3
+pars_test_bytes_sum 555
+pars_test_bytes_count 3
+`
+	if out.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+)
+
+var ErrDuplicateMetric = errors.New("metric already registered")
+
+// Registry names histograms for export in the Prometheus text format
+type Registry struct {
+	mu         sync.Mutex
+	histograms map[string]namedHistogram
+}
+
+type namedHistogram struct {
+	help string
+	h    *Histogram
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{histograms: make(map[string]namedHistogram)}
+}
+
+// Register exports h under name, which must be unique in r
+func (r *Registry) Register(name, help string, h *Histogram) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.histograms[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateMetric, name)
+	}
+	r.histograms[name] = namedHistogram{help: help, h: h}
+	return nil
+}
+
+// WritePrometheus writes every registered histogram to w in the
+// Prometheus text exposition format, sorted by name
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	histograms := maps.Clone(r.histograms)
+	r.mu.Unlock()
+
+	for _, name := range slices.Sorted(maps.Keys(histograms)) {
+		nh := histograms[name]
+		s := nh.h.Snapshot()
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, nh.help, name); err != nil {
+			return err
+		}
+		// Prometheus buckets are cumulative
+		var cumulative uint64
+		for i, bound := range s.Bounds {
+			cumulative += s.Counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%d\"} %d\n", name, bound, cumulative); err != nil {
+				return err
+			}
+		}
+		count := s.Count()
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %d\n%s_count %d\n",
+			name, count, name, s.Sum, name, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
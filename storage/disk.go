@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrDiskFull is returned when free disk space drops below the configured minimum
+var ErrDiskFull = errors.New("insufficient free disk space")
+
+// diskFree reports the bytes available to the process on the filesystem
+// holding path. It is a variable so tests can stub it.
+var diskFree = freeBytes
+
+// CheckDiskSpace returns ErrDiskFull if the filesystem holding path has
+// fewer than min bytes available. A zero min or empty path disables the check.
+// The path does not need to exist yet; its nearest existing parent is checked.
+func CheckDiskSpace(path string, min uint64) error {
+	if min == 0 || path == "" {
+		return nil
+	}
+
+	dir := existingParent(path)
+	free, err := diskFree(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check free space on %s: %w", dir, err)
+	}
+	if free < min {
+		return fmt.Errorf("%w: %s has %d bytes available, need at least %d", ErrDiskFull, dir, free, min)
+	}
+	return nil
+}
+
+// existingParent returns path or its closest ancestor that exists
+func existingParent(path string) string {
+	path = filepath.Clean(path)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
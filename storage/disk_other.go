@@ -0,0 +1,10 @@
+//go:build !unix
+
+package storage
+
+import "math"
+
+// Free space is not checked on this platform
+func freeBytes(path string) (uint64, error) {
+	return math.MaxUint64, nil
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func stubDiskFree(t *testing.T, free uint64) {
+	t.Helper()
+	orig := diskFree
+	diskFree = func(string) (uint64, error) { return free, nil }
+	t.Cleanup(func() { diskFree = orig })
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		free uint64
+		min  uint64
+		err  error
+	}{
+		{"plenty", 10 << 30, 1 << 30, nil},
+		{"exact", 1 << 30, 1 << 30, nil},
+		{"short", 512 << 20, 1 << 30, ErrDiskFull},
+		{"disabled", 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		stubDiskFree(t, tt.free)
+		if err := CheckDiskSpace(dir, tt.min); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestCheckDiskSpaceMissingDir(t *testing.T) {
+	dir := t.TempDir()
+
+	var checked string
+	orig := diskFree
+	diskFree = func(path string) (uint64, error) {
+		checked = path
+		return 1 << 40, nil
+	}
+	t.Cleanup(func() { diskFree = orig })
+
+	if err := CheckDiskSpace(filepath.Join(dir, "not", "yet", "created"), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if checked != dir {
+		t.Errorf("expected nearest existing parent %s, got %s", dir, checked)
+	}
+}
+
+func TestStoreDiskFull(t *testing.T) {
+	stubDiskFree(t, 1024)
+
+	node, err := NewNode(config.StorageConfig{
+		Enabled:      true,
+		DataDir:      t.TempDir(),
+		MinFreeBytes: 1 << 20,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := node.Store(context.Background(), "key", []byte("data"), 60); !errors.Is(err, ErrDiskFull) {
+		t.Errorf("expected ErrDiskFull, got %v", err)
+	}
+}
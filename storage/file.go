@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/luxfi/crypto/blake2b"
+)
+
+// objectMagic starts every object file, identifying the header format
+const objectMagic = "PRS1"
+
+// headerSize is the fixed part of the header: magic, created-at in Unix
+// nanoseconds, TTL in seconds and key length
+const headerSize = len(objectMagic) + 8 + 8 + 2
+
+// maxKeyLen bounds keys so their length fits the header
+const maxKeyLen = 1<<16 - 1
+
+var errCorruptObject = errors.New("corrupt storage object")
+
+// objectMeta is the header stored ahead of each object's data
+type objectMeta struct {
+	Key     string
+	Created int64 // Unix nanoseconds
+	TTL     int64 // Seconds
+}
+
+// objectPath returns the file for key. Objects live in the directory of
+// their key's session (see sessionDir), named by the hex of a digest of
+// the key so any key makes a valid file name.
+func objectPath(root, key string) string {
+	session, _, ok := strings.Cut(key, keySep)
+	if !ok {
+		session = ""
+	}
+	return filepath.Join(sessionDir(root, session), digestName(key))
+}
+
+// sessionDir returns the directory holding every object of sessionID, so
+// listing or deleting a session never touches another's objects. Keys
+// outside any session share the directory of the empty session.
+func sessionDir(root, sessionID string) string {
+	return filepath.Join(root, digestName(sessionID))
+}
+
+// digestName returns the hex of a digest of s, for use as a file name
+func digestName(s string) string {
+	sum := blake2b.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeObject returns the file contents for data under meta
+func encodeObject(meta objectMeta, data []byte) []byte {
+	b := make([]byte, 0, headerSize+len(meta.Key)+len(data))
+	b = append(b, objectMagic...)
+	b = binary.BigEndian.AppendUint64(b, uint64(meta.Created))
+	b = binary.BigEndian.AppendUint64(b, uint64(meta.TTL))
+	b = binary.BigEndian.AppendUint16(b, uint16(len(meta.Key)))
+	b = append(b, meta.Key...)
+	return append(b, data...)
+}
+
+// readMeta reads the header from the start of r
+func readMeta(r io.Reader) (objectMeta, error) {
+	var h [headerSize]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return objectMeta{}, fmt.Errorf("%w: %v", errCorruptObject, err)
+	}
+	if !bytes.Equal(h[:len(objectMagic)], []byte(objectMagic)) {
+		return objectMeta{}, fmt.Errorf("%w: bad magic", errCorruptObject)
+	}
+	b := h[len(objectMagic):]
+	meta := objectMeta{
+		Created: int64(binary.BigEndian.Uint64(b)),
+		TTL:     int64(binary.BigEndian.Uint64(b[8:])),
+	}
+	key := make([]byte, binary.BigEndian.Uint16(b[16:]))
+	if _, err := io.ReadFull(r, key); err != nil {
+		return objectMeta{}, fmt.Errorf("%w: %v", errCorruptObject, err)
+	}
+	meta.Key = string(key)
+	return meta, nil
+}
+
+// decodeObject splits file contents into header and data
+func decodeObject(b []byte) (objectMeta, []byte, error) {
+	r := bytes.NewReader(b)
+	meta, err := readMeta(r)
+	if err != nil {
+		return objectMeta{}, nil, err
+	}
+	return meta, b[len(b)-r.Len():], nil
+}
+
+// readObjectMeta reads just the header of the object file at path
+func readObjectMeta(path string) (objectMeta, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return objectMeta{}, err
+	}
+	defer f.Close()
+	return readMeta(f)
+}
+
+// writeFileAtomic writes b to path through a temporary file, so readers
+// never see a partial object
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// sessionFiles returns the object files in dir, one session's directory.
+// A missing directory holds no objects.
+func sessionFiles(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(entries, func(e os.DirEntry) bool {
+		return !e.Type().IsRegular() || e.Name()[0] == '.'
+	}), nil
+}
+
+// objectFiles returns the paths of every object file under root, session
+// directory by session directory
+func objectFiles(root string) ([]string, error) {
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, d.Name())
+		files, err := sessionFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			paths = append(paths, filepath.Join(dir, f.Name()))
+		}
+	}
+	return paths, nil
+}
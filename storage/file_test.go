@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestStoreRetrieveDelete(t *testing.T) {
+	dir := t.TempDir()
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	key, err := MessageKey("07aa", "m1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := []byte("sealed ciphertext")
+	if err := node.Store(ctx, key, data, 60); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	got, err := node.Retrieve(ctx, key)
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("expected %q, got %q", data, got)
+	}
+
+	// A reopened node reads the same objects and quota usage
+	reopened, err := NewNode(config.StorageConfig{Enabled: true, DataDir: dir})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if reopened.Used() != node.Used() || node.Used() == 0 {
+		t.Errorf("expected reopened usage %d, got %d", node.Used(), reopened.Used())
+	}
+	if got, err := reopened.Retrieve(ctx, key); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("reopened retrieve: got %q, %v", got, err)
+	}
+
+	if err := node.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := node.Retrieve(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if node.Used() != 0 {
+		t.Errorf("expected no usage after delete, got %d", node.Used())
+	}
+	if err := node.Delete(ctx, key); err != nil {
+		t.Errorf("delete of absent key: unexpected error: %v", err)
+	}
+	if got := node.Removals()[RemovedExplicit]; got != 1 {
+		t.Errorf("expected 1 explicit removal, got %d", got)
+	}
+}
+
+func TestStoreQuota(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir(), MaxSize: 256})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	data := make([]byte, 100)
+	if err := node.Store(ctx, "a", data, 60); err != nil {
+		t.Fatalf("store a: %v", err)
+	}
+	if err := node.Store(ctx, "b", data, 60); err != nil {
+		t.Fatalf("store b: %v", err)
+	}
+	if err := node.Store(ctx, "c", data, 60); !errors.Is(err, ErrStorageFull) {
+		t.Errorf("expected ErrStorageFull, got %v", err)
+	}
+	if _, err := node.Retrieve(ctx, "c"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected rejected object absent, got %v", err)
+	}
+
+	// Replacing an object counts only the difference
+	if err := node.Store(ctx, "b", data, 60); err != nil {
+		t.Errorf("replace b: unexpected error: %v", err)
+	}
+	if err := node.Delete(ctx, "a"); err != nil {
+		t.Fatalf("delete a: %v", err)
+	}
+	if err := node.Store(ctx, "c", data, 60); err != nil {
+		t.Errorf("store c after delete: unexpected error: %v", err)
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	var keys []string
+	for _, session := range []string{"07aa", "07aa", "07bb"} {
+		key, err := MessageKey(session, "m"+string(rune('0'+len(keys))))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := node.Store(ctx, key, []byte("data"), 60); err != nil {
+			t.Fatalf("store: %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := node.DeleteSession(ctx, "07aa"); err != nil {
+		t.Fatalf("delete session: %v", err)
+	}
+	for _, key := range keys[:2] {
+		if _, err := node.Retrieve(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", key, err)
+		}
+	}
+	if _, err := node.Retrieve(ctx, keys[2]); err != nil {
+		t.Errorf("other session: unexpected error: %v", err)
+	}
+	if got := node.Removals()[RemovedExplicit]; got != 2 {
+		t.Errorf("expected 2 explicit removals, got %d", got)
+	}
+
+	// Each session's objects live in its own directory
+	if _, err := os.Stat(sessionDir(node.cfg.DataDir, "07aa")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected deleted session's directory removed, got %v", err)
+	}
+	if files, err := sessionFiles(sessionDir(node.cfg.DataDir, "07bb")); err != nil || len(files) != 1 {
+		t.Errorf("expected 1 object in the other session's directory, got %d, %v", len(files), err)
+	}
+}
+
+func TestNewNodeRequiresDataDir(t *testing.T) {
+	if _, err := NewNode(config.StorageConfig{Enabled: true}); !errors.Is(err, ErrNoStorageDir) {
+		t.Errorf("expected ErrNoStorageDir, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrExpired = errors.New("stored object expired")
+
+// expiry returns when meta's object expires and why: at the earlier of its
+// TTL and the RetentionDays ceiling. ok is false if neither applies.
+func (n *Node) expiry(meta objectMeta) (at time.Time, reason RemovalReason, ok bool) {
+	created := time.Unix(0, meta.Created)
+	if meta.TTL > 0 {
+		at, reason, ok = created.Add(time.Duration(meta.TTL)*time.Second), RemovedTTL, true
+	}
+	if days := n.cfg.RetentionDays; days > 0 {
+		if ceiling := created.AddDate(0, 0, days); !ok || ceiling.Before(at) {
+			at, reason, ok = ceiling, RemovedRetention, true
+		}
+	}
+	return at, reason, ok
+}
+
+// expired reports whether meta's object has expired by now, and why
+func (n *Node) expired(meta objectMeta, now time.Time) (RemovalReason, bool) {
+	at, reason, ok := n.expiry(meta)
+	return reason, ok && !now.Before(at)
+}
+
+// RunGC performs one sweep, deleting every expired object. Followers only
+// report; their primary collects.
+func (n *Node) RunGC(ctx context.Context) error {
+	if n.ReadOnly() {
+		return nil
+	}
+	paths, err := objectFiles(n.cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	now := n.now()
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := n.collect(path, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect removes the object at path if it has expired by now. The header
+// is read under n.mu so a Store replacing the object between check and
+// removal cannot lose its new object.
+func (n *Node) collect(path string, now time.Time) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	meta, err := readObjectMeta(path)
+	if err != nil {
+		// Removed since the listing, or unreadable; skip it
+		return nil
+	}
+	reason, ok := n.expired(meta, now)
+	if !ok {
+		return nil
+	}
+	return n.remove(path, reason)
+}
+
+// runGC sweeps every interval until ctx is done
+func (n *Node) runGC(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A failed sweep is retried on the next tick
+			_ = n.RunGC(ctx)
+		}
+	}
+}
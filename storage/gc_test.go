@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestRunGC(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir(), RetentionDays: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	node.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for key, ttl := range map[string]int64{"short": 60, "long": 3600, "forever": 0} {
+		if err := node.Store(ctx, key, []byte("data"), ttl); err != nil {
+			t.Fatalf("store %s: %v", key, err)
+		}
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := node.Retrieve(ctx, "short"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired before collection, got %v", err)
+	}
+	if err := node.RunGC(ctx); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if _, err := node.Retrieve(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after collection, got %v", err)
+	}
+	for _, key := range []string{"long", "forever"} {
+		if _, err := node.Retrieve(ctx, key); err != nil {
+			t.Errorf("%s: unexpected error: %v", key, err)
+		}
+	}
+
+	// RetentionDays caps objects without a TTL
+	now = now.Add(24 * time.Hour)
+	if err := node.RunGC(ctx); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	got := node.Removals()
+	if got[RemovedTTL] != 2 || got[RemovedRetention] != 1 {
+		t.Errorf("expected 2 ttl and 1 retention removals, got %v", got)
+	}
+	if node.Used() != 0 {
+		t.Errorf("expected no usage after collection, got %d", node.Used())
+	}
+}
+
+func TestGCLoopStops(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir(), GCInterval: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := node.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		node.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not terminate the GC loop")
+	}
+}
+
+func TestScanSkipsExpired(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	node.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for key, ttl := range map[string]int64{"07aa/live": 3600, "07aa/stale": 60, "07bb/live": 3600} {
+		if err := node.Store(ctx, key, []byte(key), ttl); err != nil {
+			t.Fatalf("store %s: %v", key, err)
+		}
+	}
+	now = now.Add(2 * time.Minute)
+
+	got, err := node.Scan(ctx, "07aa")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || string(got[0]) != "07aa/live" {
+		t.Errorf("expected only 07aa/live, got %q", got)
+	}
+}
+
+func TestCollectRechecksReplacedObject(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	node.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := node.Store(ctx, "07aa/m", []byte("old"), 60); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+
+	// The sweep listed the expired object, then a Store replaced it
+	if err := node.Store(ctx, "07aa/m", []byte("new"), 60); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if err := node.collect(objectPath(node.cfg.DataDir, "07aa/m"), now); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if got, err := node.Retrieve(ctx, "07aa/m"); err != nil || string(got) != "new" {
+		t.Errorf("expected replacement kept, got %q, %v", got, err)
+	}
+}
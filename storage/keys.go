@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/luxfi/crypto/blake2b"
+)
+
+// keySep separates the session namespace from the message part of a key
+const keySep = "/"
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// MessageKey returns the storage key for a message: the session ID as a
+// namespace, then a digest binding the message ID to that session. Keys
+// for one session never collide with or reveal another's, and all of a
+// session's messages share SessionPrefix.
+func MessageKey(sessionID, messageID string) (string, error) {
+	if sessionID == "" || strings.Contains(sessionID, keySep) {
+		return "", fmt.Errorf("%w: session ID %q", ErrInvalidKey, sessionID)
+	}
+	if messageID == "" {
+		return "", fmt.Errorf("%w: empty message ID", ErrInvalidKey)
+	}
+
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte(sessionID))
+	h.Write([]byte{0})
+	h.Write([]byte(messageID))
+	return SessionPrefix(sessionID) + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SessionPrefix returns the prefix shared by every key of a session
+func SessionPrefix(sessionID string) string {
+	return sessionID + keySep
+}
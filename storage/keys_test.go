@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestMessageKeyPerSession(t *testing.T) {
+	const (
+		alice = "07aa"
+		bob   = "07bb"
+	)
+
+	a, err := MessageKey(alice, "msg-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := MessageKey(bob, "msg-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if a == b {
+		t.Error("same message ID in different sessions produced the same key")
+	}
+	if !strings.HasPrefix(a, SessionPrefix(alice)) || strings.HasPrefix(a, SessionPrefix(bob)) {
+		t.Errorf("key %s not namespaced to session %s", a, alice)
+	}
+	if strings.Contains(a, "msg-1") {
+		t.Errorf("key %s reveals the message ID", a)
+	}
+	if again, _ := MessageKey(alice, "msg-1"); again != a {
+		t.Errorf("expected stable key, got %s and %s", a, again)
+	}
+
+	// A session ID that is a prefix of another must not share its keys
+	if strings.HasPrefix(a, SessionPrefix(alice[:3])) {
+		t.Errorf("key %s falls under the prefix of session %s", a, alice[:3])
+	}
+
+	for _, tt := range []struct{ session, message string }{
+		{"", "msg-1"},
+		{"07aa/x", "msg-1"},
+		{alice, ""},
+	} {
+		if _, err := MessageKey(tt.session, tt.message); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%q/%q: expected ErrInvalidKey, got %v", tt.session, tt.message, err)
+		}
+	}
+}
+
+func TestDeleteSessionInvalid(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := node.DeleteSession(context.Background(), "07aa/x"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
@@ -3,49 +3,317 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/metrics"
 )
 
-// Node is a storage node for encrypted messages
+var (
+	ErrReadOnly     = errors.New("storage node is a read-only follower")
+	ErrNotFound     = errors.New("storage key not found")
+	ErrStorageFull  = errors.New("storage quota exceeded")
+	ErrNoStorageDir = errors.New("storage data directory not set")
+)
+
+// Node is a storage node for encrypted messages, kept as one file per
+// object under cfg.DataDir (see objectPath). Every operation returns
+// ctx.Err() without touching storage once its context is done.
 type Node struct {
 	cfg     config.StorageConfig
 	running bool
+
+	// mu serializes writes and guards used
+	mu   sync.Mutex
+	used uint64 // Bytes of object files on disk
+
+	// Set for a follower until Promote
+	readOnly atomic.Bool
+
+	// Objects removed, indexed by RemovalReason
+	removals [numRemovalReasons]atomic.Uint64
+
+	// Sizes of objects accepted by Store
+	sizes *metrics.Histogram
+
+	// Stop the GC and replication loops started by Start and wait for them
+	stopGC        func()
+	stopReplicate func()
+
+	// now is the storage clock, replaceable in tests
+	now func() time.Time
 }
 
-// NewNode creates a new storage node
+// NewNode opens the storage node rooted at cfg.DataDir, creating the
+// directory if needed
 func NewNode(cfg config.StorageConfig) (*Node, error) {
-	return &Node{
-		cfg: cfg,
-	}, nil
+	if cfg.DataDir == "" {
+		return nil, ErrNoStorageDir
+	}
+	if err := os.MkdirAll(cfg.DataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	n := &Node{
+		cfg:   cfg,
+		sizes: metrics.NewSizeHistogram(),
+		now:   time.Now,
+	}
+	n.readOnly.Store(cfg.Role == "follower")
+
+	paths, err := objectFiles(cfg.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan storage directory: %w", err)
+	}
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err == nil {
+			n.used += uint64(fi.Size())
+		}
+	}
+	return n, nil
+}
+
+// ReadOnly reports whether the node is a follower rejecting writes
+func (n *Node) ReadOnly() bool {
+	return n.readOnly.Load()
 }
 
-// Start starts the storage node
+// Promote makes a follower a primary that accepts writes, e.g. on
+// failover
+func (n *Node) Promote() {
+	n.readOnly.Store(false)
+}
+
+// Start starts the storage node and, unless cfg.GCInterval is 0, a
+// background loop deleting expired objects. A follower with a PrimaryDir
+// also replicates from it every cfg.ReplicateInterval seconds.
 func (n *Node) Start(ctx context.Context) error {
 	n.running = true
-	// TODO: Initialize storage backend
+	if n.cfg.GCInterval > 0 && n.stopGC == nil {
+		n.stopGC = startLoop(ctx, n.runGC, time.Duration(n.cfg.GCInterval)*time.Second)
+	}
+	if n.ReadOnly() && n.cfg.PrimaryDir != "" && n.cfg.ReplicateInterval > 0 && n.stopReplicate == nil {
+		n.stopReplicate = startLoop(ctx, n.runReplication, time.Duration(n.cfg.ReplicateInterval)*time.Second)
+	}
 	return nil
 }
 
-// Stop stops the storage node
+// startLoop runs loop in the background, returning a function that stops
+// it and waits for it to return
+func startLoop(ctx context.Context, loop func(context.Context, time.Duration), interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		loop(ctx, interval)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Stop stops the storage node, waiting for any GC sweep or replication
+// in progress
 func (n *Node) Stop() {
 	n.running = false
+	for _, stop := range []*func(){&n.stopGC, &n.stopReplicate} {
+		if *stop != nil {
+			(*stop)()
+			*stop = nil
+		}
+	}
 }
 
-// Store stores an encrypted message
+// Store stores an encrypted message under key, replacing any earlier
+// object. It returns ErrStorageFull if the write would take the node past
+// cfg.MaxSize.
 func (n *Node) Store(ctx context.Context, key string, data []byte, ttl int64) error {
-	// TODO: Store encrypted data with TTL
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n.ReadOnly() {
+		return ErrReadOnly
+	}
+	if key == "" || len(key) > maxKeyLen {
+		return fmt.Errorf("%w: key of %d bytes", ErrInvalidKey, len(key))
+	}
+	if err := CheckDiskSpace(n.cfg.DataDir, n.cfg.MinFreeBytes); err != nil {
+		return err
+	}
+
+	b := encodeObject(objectMeta{Key: key, Created: n.now().UnixNano(), TTL: ttl}, data)
+	path := objectPath(n.cfg.DataDir, key)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var replaced uint64
+	if fi, err := os.Stat(path); err == nil {
+		replaced = uint64(fi.Size())
+	}
+	if total := n.used - replaced + uint64(len(b)); n.cfg.MaxSize > 0 && total > n.cfg.MaxSize {
+		return fmt.Errorf("%w: %d of %d bytes used, object needs %d", ErrStorageFull, n.used, n.cfg.MaxSize, len(b))
+	}
+	if err := writeFileAtomic(path, b); err != nil {
+		return fmt.Errorf("failed to store %q: %w", key, err)
+	}
+	n.used = n.used - replaced + uint64(len(b))
+	n.sizes.Observe(uint64(len(data)))
 	return nil
 }
 
-// Retrieve retrieves stored data
+// Used returns the bytes of object files on disk, as counted against
+// cfg.MaxSize
+func (n *Node) Used() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.used
+}
+
+// StoredSizes returns the distribution of object sizes stored so far
+func (n *Node) StoredSizes() metrics.HistogramSnapshot {
+	return n.sizes.Snapshot()
+}
+
+// RegisterMetrics exports the stored size histogram to r
+func (n *Node) RegisterMetrics(r *metrics.Registry) error {
+	return r.Register("pars_storage_object_bytes", "Size of objects stored", n.sizes)
+}
+
+// Retrieve retrieves the data stored under key. It returns ErrNotFound
+// for absent keys and ErrExpired for objects awaiting collection.
 func (n *Node) Retrieve(ctx context.Context, key string) ([]byte, error) {
-	// TODO: Retrieve encrypted data
-	return nil, nil
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(objectPath(n.cfg.DataDir, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", key, err)
+	}
+	meta, data, err := decodeObject(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", key, err)
+	}
+	if meta.Key != key {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	if _, ok := n.expired(meta, n.now()); ok {
+		return nil, fmt.Errorf("%w: %q", ErrExpired, key)
+	}
+	return data, nil
 }
 
-// Delete deletes stored data
+// Scan returns the data of every unexpired object stored for sessionID,
+// i.e. under SessionPrefix(sessionID), in no particular order. Only the
+// session's own directory is read.
+func (n *Node) Scan(ctx context.Context, sessionID string) ([][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if sessionID == "" || strings.Contains(sessionID, keySep) {
+		return nil, fmt.Errorf("%w: session ID %q", ErrInvalidKey, sessionID)
+	}
+	dir := sessionDir(n.cfg.DataDir, sessionID)
+	files, err := sessionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := SessionPrefix(sessionID)
+	now := n.now()
+	var out [][]byte
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			// Deleted since the listing
+			continue
+		}
+		meta, data, err := decodeObject(b)
+		if err != nil || !strings.HasPrefix(meta.Key, prefix) {
+			continue
+		}
+		if _, ok := n.expired(meta, now); ok {
+			continue
+		}
+		out = append(out, data)
+	}
+	return out, nil
+}
+
+// Delete deletes the object stored under key, data and metadata alike.
+// Deleting an absent key is not an error.
 func (n *Node) Delete(ctx context.Context, key string) error {
-	// TODO: Delete data
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n.ReadOnly() {
+		return ErrReadOnly
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.remove(objectPath(n.cfg.DataDir, key), RemovedExplicit)
+}
+
+// remove deletes the object file at path, recording reason if it existed.
+// The caller holds n.mu.
+func (n *Node) remove(path string, reason RemovalReason) error {
+	fi, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	n.used -= min(uint64(fi.Size()), n.used)
+	n.recordRemoval(reason)
+	return nil
+}
+
+// DeleteSession deletes every message stored for sessionID, i.e. all keys
+// under SessionPrefix(sessionID), by removing the session's directory
+func (n *Node) DeleteSession(ctx context.Context, sessionID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n.ReadOnly() {
+		return ErrReadOnly
+	}
+	if sessionID == "" || strings.Contains(sessionID, keySep) {
+		return fmt.Errorf("%w: session ID %q", ErrInvalidKey, sessionID)
+	}
+
+	dir := sessionDir(n.cfg.DataDir, sessionID)
+	files, err := sessionFiles(dir)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := n.remove(filepath.Join(dir, f.Name()), RemovedExplicit); err != nil {
+			return err
+		}
+	}
+	// Leave the directory if a concurrent Store has refilled it
+	_ = os.Remove(dir)
 	return nil
 }
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestCancelledContext(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := node.Store(ctx, "a", []byte("data"), 60); !errors.Is(err, context.Canceled) {
+		t.Errorf("store: expected context.Canceled, got %v", err)
+	}
+	if _, err := node.Retrieve(ctx, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("retrieve: expected context.Canceled, got %v", err)
+	}
+	if err := node.Delete(ctx, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("delete: expected context.Canceled, got %v", err)
+	}
+
+	if got := node.Removals()[RemovedExplicit]; got != 0 {
+		t.Errorf("expected no removal recorded for a cancelled delete, got %d", got)
+	}
+	if got := node.StoredSizes().Count(); got != 0 {
+		t.Errorf("expected no size recorded for a cancelled store, got %d", got)
+	}
+}
+
+func TestFollowerReadOnly(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir(), Role: "follower"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := node.Store(ctx, "a", []byte("data"), 60); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("store: expected ErrReadOnly, got %v", err)
+	}
+	if err := node.Delete(ctx, "a"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("delete: expected ErrReadOnly, got %v", err)
+	}
+	if err := node.DeleteSession(ctx, "07aa"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("delete session: expected ErrReadOnly, got %v", err)
+	}
+	if _, err := node.Retrieve(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("retrieve: expected reads served, got %v", err)
+	}
+
+	node.Promote()
+	if err := node.Store(ctx, "a", []byte("data"), 60); err != nil {
+		t.Errorf("store after promote: unexpected error: %v", err)
+	}
+}
+
+func TestFollowerReplicates(t *testing.T) {
+	ctx := context.Background()
+	primaryDir := t.TempDir()
+	primary, err := NewNode(config.StorageConfig{Enabled: true, DataDir: primaryDir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	follower, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir(), Role: "follower", PrimaryDir: primaryDir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"07aa/m1", "07aa/m2", "07bb/m1"} {
+		if err := primary.Store(ctx, key, []byte("data "+key), 60); err != nil {
+			t.Fatalf("store %s: %v", key, err)
+		}
+	}
+	if err := follower.Replicate(ctx); err != nil {
+		t.Fatalf("replicate: %v", err)
+	}
+	data, err := follower.Retrieve(ctx, "07aa/m2")
+	if err != nil || string(data) != "data 07aa/m2" {
+		t.Errorf("expected the follower to serve replicated data, got %q, %v", data, err)
+	}
+	if follower.Used() != primary.Used() {
+		t.Errorf("expected %d bytes used, got %d", primary.Used(), follower.Used())
+	}
+
+	// Changes and deletions on the primary follow on the next pass
+	if err := primary.Store(ctx, "07aa/m1", []byte("replaced"), 60); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := primary.DeleteSession(ctx, "07bb"); err != nil {
+		t.Fatalf("delete session: %v", err)
+	}
+	if err := follower.Replicate(ctx); err != nil {
+		t.Fatalf("replicate: %v", err)
+	}
+	if data, err := follower.Retrieve(ctx, "07aa/m1"); err != nil || string(data) != "replaced" {
+		t.Errorf("expected the replaced object, got %q, %v", data, err)
+	}
+	if _, err := follower.Retrieve(ctx, "07bb/m1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the deleted object gone, got %v", err)
+	}
+	if got := follower.Removals()[RemovedReplica]; got != 1 {
+		t.Errorf("expected 1 replica removal, got %d", got)
+	}
+	if follower.Used() != primary.Used() {
+		t.Errorf("expected %d bytes used, got %d", primary.Used(), follower.Used())
+	}
+
+	// Once promoted the follower stops mirroring and takes writes
+	follower.Promote()
+	if err := primary.Delete(ctx, "07aa/m2"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := follower.Replicate(ctx); err != nil {
+		t.Fatalf("replicate: %v", err)
+	}
+	if _, err := follower.Retrieve(ctx, "07aa/m2"); err != nil {
+		t.Errorf("expected a promoted follower to keep its data, got %v", err)
+	}
+
+	orphan, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir(), Role: "follower"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := orphan.Replicate(ctx); !errors.Is(err, ErrNoPrimary) {
+		t.Errorf("expected ErrNoPrimary, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+// RemovalReason records why a stored object was removed
+type RemovalReason int
+
+const (
+	RemovedExplicit  RemovalReason = iota // Delete called by a client
+	RemovedTTL                            // Per-object TTL elapsed
+	RemovedRetention                      // RetentionDays ceiling reached
+	RemovedReplica                        // Gone from a follower's primary
+	numRemovalReasons
+)
+
+// String returns the metric label for the reason
+func (r RemovalReason) String() string {
+	switch r {
+	case RemovedExplicit:
+		return "explicit"
+	case RemovedTTL:
+		return "ttl"
+	case RemovedRetention:
+		return "retention"
+	case RemovedReplica:
+		return "replica"
+	default:
+		return "unknown"
+	}
+}
+
+// Removals returns the number of objects removed so far, by reason
+func (n *Node) Removals() map[RemovalReason]uint64 {
+	counts := make(map[RemovalReason]uint64, numRemovalReasons)
+	for r := RemovalReason(0); r < numRemovalReasons; r++ {
+		counts[r] = n.removals[r].Load()
+	}
+	return counts
+}
+
+func (n *Node) recordRemoval(reason RemovalReason) {
+	n.removals[reason].Add(1)
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+func TestRemovalsByReason(t *testing.T) {
+	node, err := NewNode(config.StorageConfig{Enabled: true, DataDir: t.TempDir(), RetentionDays: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	node.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for key, ttl := range map[string]int64{"a": 60, "b": 60, "short": 60, "forever": 0} {
+		if err := node.Store(ctx, key, []byte("data"), ttl); err != nil {
+			t.Fatalf("store %s: %v", key, err)
+		}
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := node.Delete(ctx, key); err != nil {
+			t.Fatalf("delete %s: %v", key, err)
+		}
+	}
+
+	// The sweep removes "short" for its TTL, then "forever" for retention
+	now = now.Add(2 * time.Minute)
+	if err := node.RunGC(ctx); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	now = now.Add(24 * time.Hour)
+	if err := node.RunGC(ctx); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+
+	got := node.Removals()
+	want := map[RemovalReason]uint64{RemovedExplicit: 2, RemovedTTL: 1, RemovedRetention: 1, RemovedReplica: 0}
+	for reason, n := range want {
+		if got[reason] != n {
+			t.Errorf("expected %d %s removals, got %d", n, reason, got[reason])
+		}
+	}
+}
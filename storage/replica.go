@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ErrNoPrimary = errors.New("follower has no primary directory")
+
+// Replicate makes a follower's objects match its primary's: objects new or
+// changed in cfg.PrimaryDir are copied, and objects the primary no longer
+// holds are removed. Unchanged objects, by size and modification time, are
+// skipped. A primary, including a promoted follower, does nothing.
+func (n *Node) Replicate(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !n.ReadOnly() {
+		return nil
+	}
+	primary := n.cfg.PrimaryDir
+	if primary == "" {
+		return ErrNoPrimary
+	}
+
+	paths, err := objectFiles(primary)
+	if err != nil {
+		return fmt.Errorf("failed to list primary: %w", err)
+	}
+	held := make(map[string]bool, len(paths))
+	for _, src := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(primary, src)
+		if err != nil {
+			return err
+		}
+		held[rel] = true
+		if err := n.copyObject(src, filepath.Join(n.cfg.DataDir, rel)); err != nil {
+			return err
+		}
+	}
+
+	local, err := objectFiles(n.cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, dst := range local {
+		rel, err := filepath.Rel(n.cfg.DataDir, dst)
+		if err != nil {
+			return err
+		}
+		if held[rel] {
+			continue
+		}
+		if err := n.remove(dst, RemovedReplica); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyObject copies the primary's object file src to dst unless dst
+// already has its size and modification time
+func (n *Node) copyObject(src, dst string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		// Removed on the primary since the listing
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var replaced uint64
+	if dstInfo, err := os.Stat(dst); err == nil {
+		if dstInfo.Size() == srcInfo.Size() && dstInfo.ModTime().Equal(srcInfo.ModTime()) {
+			return nil
+		}
+		replaced = uint64(dstInfo.Size())
+	}
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return nil
+	}
+	if _, _, err := decodeObject(b); err != nil {
+		// Not an object, or caught mid-write; retried next pass
+		return nil
+	}
+	if err := writeFileAtomic(dst, b); err != nil {
+		return fmt.Errorf("failed to replicate %s: %w", src, err)
+	}
+	if err := os.Chtimes(dst, time.Time{}, srcInfo.ModTime()); err != nil {
+		return err
+	}
+	n.used = n.used - min(replaced, n.used) + uint64(len(b))
+	return nil
+}
+
+// runReplication replicates every interval until ctx is done
+func (n *Node) runReplication(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A failed pass is retried on the next tick
+			_ = n.Replicate(ctx)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Package version holds parsd build metadata, stamped at link time:
+//
+//	go build -ldflags "-X github.com/parsdao/node/version.Version=v1.2.3 \
+//	  -X github.com/parsdao/node/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/parsdao/node/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+// Set with -ldflags -X; the defaults mark an unstamped build
+var (
+	Version = "dev"     // Semantic version, e.g. v1.2.3
+	Commit  = "unknown" // Git commit hash
+	Date    = "unknown" // Build date, RFC 3339
+)
+
+// GoVersion returns the Go toolchain parsd was built with
+func GoVersion() string {
+	return runtime.Version()
+}
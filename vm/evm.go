@@ -3,26 +3,43 @@ package vm
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/parsdao/node/config"
 )
 
+// ErrMissingPrecompile is returned by CheckPrecompiles in "fail" mode
+var ErrMissingPrecompile = errors.New("configured precompile missing from EVM plugin")
+
 // EVM wraps the Lux EVM with PQ precompiles
 type EVM struct {
-	cfg     config.EVMConfig
-	running bool
+	cfg      config.EVMConfig
+	features config.Features
+	rpc      ethRPC
+	running  bool
+}
+
+// precompileFeatures maps experimental precompiles to their feature flag
+var precompileFeatures = map[string]string{
+	"fhe": config.FeatureFHEPrecompile,
 }
 
 // NewEVM creates a new EVM instance
-func NewEVM(cfg config.EVMConfig) (*EVM, error) {
-	if !cfg.Enabled {
-		return &EVM{cfg: cfg}, nil
-	}
+func NewEVM(cfg config.EVMConfig, features config.Features) (*EVM, error) {
+	return newEVM(cfg, features, newHTTPRPC(cfg.RPCEndpoint)), nil
+}
 
+// newEVM creates an EVM that talks to the C-Chain through rpc
+func newEVM(cfg config.EVMConfig, features config.Features, rpc ethRPC) *EVM {
 	return &EVM{
-		cfg: cfg,
-	}, nil
+		cfg:      cfg,
+		features: features,
+		rpc:      rpc,
+	}
 }
 
 // Name returns the VM name
@@ -64,11 +81,136 @@ func (e *EVM) Health() HealthStatus {
 	return HealthStatus{Healthy: true}
 }
 
-// Call executes a contract call (placeholder)
+// Call executes a read-only contract call against the latest C-Chain state
 func (e *EVM) Call(ctx context.Context, to string, data []byte) ([]byte, error) {
 	if !e.running {
 		return nil, fmt.Errorf("EVM not running")
 	}
-	// TODO: Implement actual EVM call
-	return nil, nil
+	if err := e.checkPrecompileFeature(to); err != nil {
+		return nil, err
+	}
+
+	call := map[string]string{
+		"to":   to,
+		"data": "0x" + hex.EncodeToString(data),
+	}
+
+	var result string
+	if err := e.rpc.CallContext(ctx, &result, "eth_call", call, "latest"); err != nil {
+		return nil, fmt.Errorf("eth_call to %s failed: %w", to, err)
+	}
+	return decodeHex(result)
+}
+
+// ProbePrecompiles reports which configured precompiles are active on the
+// C-Chain. Active stateful precompiles carry non-empty placeholder code,
+// so eth_getCode distinguishes them from unused addresses.
+func (e *EVM) ProbePrecompiles(ctx context.Context) (map[string]bool, error) {
+	if !e.running {
+		return nil, fmt.Errorf("EVM not running")
+	}
+
+	active := make(map[string]bool)
+	for name, short := range precompileAddresses(e.cfg.Precompiles) {
+		if feature, ok := precompileFeatures[name]; ok && !e.features.Enabled(feature) {
+			continue
+		}
+
+		addr, err := precompileAddress(short)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s precompile address: %w", name, err)
+		}
+
+		var code string
+		if err := e.rpc.CallContext(ctx, &code, "eth_getCode", addr, "latest"); err != nil {
+			return nil, fmt.Errorf("failed to probe %s precompile at %s: %w", name, addr, err)
+		}
+
+		raw, err := decodeHex(code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid code for %s precompile: %w", name, err)
+		}
+		active[name] = len(raw) > 0
+	}
+	return active, nil
+}
+
+// CheckPrecompiles cross-checks the configured precompiles against those
+// the EVM plugin exposes, per cfg.PrecompileCheck. It returns the names of
+// missing precompiles for the caller to warn about; in "fail" mode it also
+// returns ErrMissingPrecompile. Call it once the C-Chain is serving RPC.
+func (e *EVM) CheckPrecompiles(ctx context.Context) ([]string, error) {
+	if e.cfg.PrecompileCheck == "off" {
+		return nil, nil
+	}
+
+	active, err := e.ProbePrecompiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for name, ok := range active {
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+	slices.Sort(missing)
+
+	if len(missing) > 0 && e.cfg.PrecompileCheck == "fail" {
+		return missing, fmt.Errorf("%w: %s", ErrMissingPrecompile, strings.Join(missing, ", "))
+	}
+	return missing, nil
+}
+
+// checkPrecompileFeature rejects calls to precompiles whose feature flag is off
+func (e *EVM) checkPrecompileFeature(to string) error {
+	for name, short := range precompileAddresses(e.cfg.Precompiles) {
+		feature, ok := precompileFeatures[name]
+		if !ok {
+			continue
+		}
+		addr, err := precompileAddress(short)
+		if err != nil || !strings.EqualFold(addr, to) {
+			continue
+		}
+		if err := e.features.Require(feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// precompileAddresses returns the configured precompiles by name
+func precompileAddresses(cfg config.PrecompileConfig) map[string]string {
+	all := map[string]string{
+		"mldsa":    cfg.MLDSA,
+		"mlkem":    cfg.MLKEM,
+		"bls":      cfg.BLS,
+		"ringtail": cfg.Ringtail,
+		"fhe":      cfg.FHE,
+	}
+	for name, addr := range all {
+		if addr == "" {
+			delete(all, name)
+		}
+	}
+	return all
+}
+
+// precompileAddress expands a short precompile address like 0x0601
+// into a full 20-byte hex address
+func precompileAddress(short string) (string, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(short, "0x"), "0X")
+	if len(digits) == 0 || len(digits) > 40 {
+		return "", fmt.Errorf("bad length: %q", short)
+	}
+	if _, err := hex.DecodeString(strings.Repeat("0", len(digits)%2) + digits); err != nil {
+		return "", fmt.Errorf("not hex: %q", short)
+	}
+	return "0x" + strings.Repeat("0", 40-len(digits)) + strings.ToLower(digits), nil
+}
+
+// decodeHex decodes a 0x-prefixed hex string
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
 }
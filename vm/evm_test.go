@@ -0,0 +1,250 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/parsdao/node/config"
+)
+
+// fakeRPC returns canned results keyed by method and first parameter
+type fakeRPC struct {
+	results map[string]interface{}
+	errs    map[string]error
+	calls   []string
+}
+
+func (f *fakeRPC) CallContext(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	key := method
+	if len(params) > 0 {
+		if s, ok := params[0].(string); ok {
+			key = method + ":" + s
+		}
+	}
+	f.calls = append(f.calls, key)
+
+	if err, ok := f.errs[key]; ok {
+		return err
+	}
+	if err, ok := f.errs[method]; ok {
+		return err
+	}
+
+	res, ok := f.results[key]
+	if !ok {
+		res, ok = f.results[method]
+	}
+	if !ok {
+		return fmt.Errorf("unexpected call %s", key)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func startedEVM(t *testing.T, cfg config.EVMConfig, rpc ethRPC) *EVM {
+	t.Helper()
+	return startedEVMWithFeatures(t, cfg, nil, rpc)
+}
+
+func startedEVMWithFeatures(t *testing.T, cfg config.EVMConfig, features config.Features, rpc ethRPC) *EVM {
+	t.Helper()
+	e := newEVM(cfg, features, rpc)
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	return e
+}
+
+func TestEVMCall(t *testing.T) {
+	rpc := &fakeRPC{results: map[string]interface{}{"eth_call": "0xdeadbeef"}}
+	e := startedEVM(t, config.Default().EVM, rpc)
+
+	out, err := e.Call(context.Background(), "0x0000000000000000000000000000000000000601", []byte{0x01})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(out, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("expected deadbeef, got %x", out)
+	}
+}
+
+func TestEVMCallError(t *testing.T) {
+	rpc := &fakeRPC{errs: map[string]error{"eth_call": &rpcError{Code: 3, Message: "execution reverted"}}}
+	e := startedEVM(t, config.Default().EVM, rpc)
+
+	_, err := e.Call(context.Background(), "0x0000000000000000000000000000000000000601", nil)
+	var rpcErr *rpcError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != 3 {
+		t.Errorf("expected rpc error code 3, got %v", err)
+	}
+}
+
+func TestEVMCallNotRunning(t *testing.T) {
+	e := newEVM(config.Default().EVM, nil, &fakeRPC{})
+	if _, err := e.Call(context.Background(), "0x00", nil); err == nil {
+		t.Error("expected error when EVM not running")
+	}
+}
+
+func TestProbePrecompiles(t *testing.T) {
+	rpc := &fakeRPC{
+		results: map[string]interface{}{
+			"eth_getCode": "0x01",
+			"eth_getCode:0x0000000000000000000000000000000000000800": "0x",
+		},
+	}
+	e := startedEVM(t, config.Default().EVM, rpc)
+
+	active, err := e.ProbePrecompiles(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"mldsa", "mlkem", "bls", "ringtail"} {
+		if !active[name] {
+			t.Errorf("expected %s active", name)
+		}
+	}
+	if active["fhe"] {
+		t.Error("expected fhe inactive")
+	}
+}
+
+func TestCheckPrecompiles(t *testing.T) {
+	features := config.Features{config.FeatureFHEPrecompile: true}
+	rpc := &fakeRPC{
+		results: map[string]interface{}{
+			"eth_getCode": "0x01",
+			"eth_getCode:0x0000000000000000000000000000000000000800": "0x",
+		},
+	}
+
+	tests := []struct {
+		mode    string
+		missing []string
+		wantErr bool
+	}{
+		{"off", nil, false},
+		{"warn", []string{"fhe"}, false},
+		{"fail", []string{"fhe"}, true},
+	}
+
+	for _, tt := range tests {
+		cfg := config.Default().EVM
+		cfg.PrecompileCheck = tt.mode
+		e := startedEVMWithFeatures(t, cfg, features, rpc)
+
+		missing, err := e.CheckPrecompiles(context.Background())
+		if errors.Is(err, ErrMissingPrecompile) != tt.wantErr {
+			t.Errorf("%s: expected ErrMissingPrecompile=%v, got %v", tt.mode, tt.wantErr, err)
+		}
+		if !slices.Equal(missing, tt.missing) {
+			t.Errorf("%s: expected missing %v, got %v", tt.mode, tt.missing, missing)
+		}
+	}
+}
+
+func TestFHEPrecompileFeatureFlag(t *testing.T) {
+	rpc := &fakeRPC{results: map[string]interface{}{"eth_call": "0x01", "eth_getCode": "0x01"}}
+	fhe := "0x0000000000000000000000000000000000000800"
+
+	e := startedEVM(t, config.Default().EVM, rpc)
+	if _, err := e.Call(context.Background(), fhe, nil); !errors.Is(err, config.ErrFeatureDisabled) {
+		t.Errorf("expected ErrFeatureDisabled, got %v", err)
+	}
+	active, err := e.ProbePrecompiles(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active["fhe"] {
+		t.Error("expected fhe reported inactive while disabled")
+	}
+
+	features := config.Features{config.FeatureFHEPrecompile: true}
+	e = startedEVMWithFeatures(t, config.Default().EVM, features, rpc)
+	if _, err := e.Call(context.Background(), fhe, nil); err != nil {
+		t.Errorf("unexpected error with feature enabled: %v", err)
+	}
+	active, err = e.ProbePrecompiles(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !active["fhe"] {
+		t.Error("expected fhe active once enabled")
+	}
+}
+
+func TestProbePrecompilesError(t *testing.T) {
+	rpc := &fakeRPC{errs: map[string]error{"eth_getCode": errors.New("connection refused")}}
+	e := startedEVM(t, config.Default().EVM, rpc)
+
+	if _, err := e.ProbePrecompiles(context.Background()); err == nil {
+		t.Error("expected probe error")
+	}
+}
+
+func TestPrecompileAddress(t *testing.T) {
+	tests := []struct {
+		short string
+		want  string
+		valid bool
+	}{
+		{"0x0601", "0x0000000000000000000000000000000000000601", true},
+		{"0x0B00", "0x0000000000000000000000000000000000000b00", true},
+		{"0x601", "0x0000000000000000000000000000000000000601", true},
+		{"0x", "", false},
+		{"0xzz", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := precompileAddress(tt.short)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.short, tt.valid, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.short, tt.want, got)
+		}
+	}
+}
+
+func TestHTTPRPC(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("bad request: %v", err)
+		}
+		switch req.Method {
+		case "eth_blockNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":"0x2a"}`, req.ID)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+		}
+	}))
+	defer srv.Close()
+
+	rpc := newHTTPRPC(srv.URL)
+
+	var height string
+	if err := rpc.CallContext(context.Background(), &height, "eth_blockNumber"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if height != "0x2a" {
+		t.Errorf("expected 0x2a, got %s", height)
+	}
+
+	var rpcErr *rpcError
+	if err := rpc.CallContext(context.Background(), nil, "eth_nope"); !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Errorf("expected method not found error, got %v", err)
+	}
+}
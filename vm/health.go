@@ -0,0 +1,180 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthChecker reports the health of a node component
+type HealthChecker interface {
+	Health() HealthStatus
+}
+
+// HealthReport is the overall node health with per-component detail
+type HealthReport struct {
+	Healthy    bool                    `json:"healthy"`
+	Components map[string]HealthStatus `json:"components"`
+}
+
+// HealthAggregator combines component health into an overall status. The
+// node is healthy only if every registered component is.
+type HealthAggregator struct {
+	mu         sync.Mutex
+	components map[string]HealthChecker
+}
+
+// NewHealthAggregator creates an empty aggregator
+func NewHealthAggregator() *HealthAggregator {
+	return &HealthAggregator{components: make(map[string]HealthChecker)}
+}
+
+// Register adds a component under name, replacing any previous one
+func (a *HealthAggregator) Register(name string, c HealthChecker) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.components[name] = c
+}
+
+// Check queries every component
+func (a *HealthAggregator) Check() HealthReport {
+	a.mu.Lock()
+	components := make(map[string]HealthChecker, len(a.components))
+	for name, c := range a.components {
+		components[name] = c
+	}
+	a.mu.Unlock()
+
+	report := HealthReport{Healthy: true, Components: make(map[string]HealthStatus, len(components))}
+	for name, c := range components {
+		status := c.Health()
+		report.Components[name] = status
+		if !status.Healthy {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+// Health implements HealthChecker, naming the unhealthy components
+func (a *HealthAggregator) Health() HealthStatus {
+	report := a.Check()
+	if report.Healthy {
+		return HealthStatus{Healthy: true}
+	}
+
+	var failing []string
+	for name, status := range report.Components {
+		if !status.Healthy {
+			failing = append(failing, name)
+		}
+	}
+	sort.Strings(failing)
+	return HealthStatus{Healthy: false, Message: "unhealthy: " + strings.Join(failing, ", ")}
+}
+
+// Connector is a component with a connection to an external endpoint,
+// such as the warp client
+type Connector interface {
+	Connected() bool
+}
+
+// ConnectionHealth reports a Connector as unhealthy while disconnected
+type ConnectionHealth struct {
+	name string
+	conn Connector
+}
+
+// NewConnectionHealth checks conn, naming it in the status message
+func NewConnectionHealth(name string, conn Connector) *ConnectionHealth {
+	return &ConnectionHealth{name: name, conn: conn}
+}
+
+// Health implements HealthChecker
+func (c *ConnectionHealth) Health() HealthStatus {
+	if !c.conn.Connected() {
+		return HealthStatus{Healthy: false, Message: c.name + " disconnected"}
+	}
+	return HealthStatus{Healthy: true}
+}
+
+// ChainHealth reports the health of a chain as seen by luxd, which
+// includes the liveness of the chain's VM plugin process
+type ChainHealth struct {
+	endpoint string
+	chain    string
+	client   *http.Client
+}
+
+// luxdHealthResponse is the body of luxd's /ext/health endpoint
+type luxdHealthResponse struct {
+	Checks  map[string]luxdHealthCheck `json:"checks"`
+	Healthy bool                       `json:"healthy"`
+}
+
+type luxdHealthCheck struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewChainHealth checks chain (an alias such as "C") against the luxd
+// HTTP API at endpoint, e.g. http://127.0.0.1:9660
+func NewChainHealth(endpoint, chain string) *ChainHealth {
+	return &ChainHealth{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		chain:    chain,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SetTimeout bounds each request to luxd, 5s by default
+func (c *ChainHealth) SetTimeout(d time.Duration) {
+	c.client.Timeout = d
+}
+
+// Health implements HealthChecker
+func (c *ChainHealth) Health() HealthStatus {
+	checks, err := c.fetch(context.Background())
+	if err != nil {
+		return HealthStatus{Healthy: false, Message: err.Error()}
+	}
+
+	check, ok := checks[c.chain]
+	if !ok {
+		return HealthStatus{Healthy: false, Message: fmt.Sprintf("chain %s not reported by luxd", c.chain)}
+	}
+	if check.Error != nil {
+		return HealthStatus{Healthy: false, Message: check.Error.Message}
+	}
+	return HealthStatus{Healthy: true}
+}
+
+func (c *ChainHealth) fetch(ctx context.Context) (map[string]luxdHealthCheck, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/ext/health", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("luxd health request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// luxd answers 503 with the same body when any check fails
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("luxd health request failed: %s", resp.Status)
+	}
+
+	var out luxdHealthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("invalid luxd health response: %w", err)
+	}
+	return out.Checks, nil
+}
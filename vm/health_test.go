@@ -0,0 +1,67 @@
+package vm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChainHealthAggregation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ext/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{
+			"checks": {
+				"C": {"message": {"consensus": "ok"}},
+				"S": {"error": {"message": "vm plugin exited"}}
+			},
+			"healthy": false
+		}`))
+	}))
+	defer srv.Close()
+
+	agg := NewHealthAggregator()
+	agg.Register("c-chain", NewChainHealth(srv.URL, "C"))
+	agg.Register("s-chain", NewChainHealth(srv.URL, "S"))
+
+	report := agg.Check()
+	if report.Healthy {
+		t.Error("expected node unhealthy with a chain plugin down")
+	}
+	if !report.Components["c-chain"].Healthy {
+		t.Errorf("expected C-Chain healthy, got %+v", report.Components["c-chain"])
+	}
+	if s := report.Components["s-chain"]; s.Healthy || s.Message != "vm plugin exited" {
+		t.Errorf("expected S-Chain unhealthy with plugin error, got %+v", s)
+	}
+
+	if status := agg.Health(); status.Healthy || !strings.Contains(status.Message, "s-chain") {
+		t.Errorf("expected summary naming s-chain, got %+v", status)
+	}
+}
+
+func TestChainHealthUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	if status := NewChainHealth(srv.URL, "C").Health(); status.Healthy {
+		t.Error("expected unhealthy when luxd is unreachable")
+	}
+}
+
+type fakeConnector bool
+
+func (c fakeConnector) Connected() bool { return bool(c) }
+
+func TestConnectionHealth(t *testing.T) {
+	if status := NewConnectionHealth("warp", fakeConnector(true)).Health(); !status.Healthy {
+		t.Errorf("expected healthy when connected, got %+v", status)
+	}
+	if status := NewConnectionHealth("warp", fakeConnector(false)).Health(); status.Healthy || status.Message != "warp disconnected" {
+		t.Errorf("expected unhealthy naming warp, got %+v", status)
+	}
+}
@@ -3,23 +3,30 @@ package vm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/parsdao/node/config"
 	"github.com/parsdao/node/messaging"
+	"github.com/parsdao/node/metrics"
 	"github.com/parsdao/node/storage"
 )
 
+// flushTimeout bounds how long Stop waits for queued messages to send
+const flushTimeout = 10 * time.Second
+
 // ParsVM handles post-quantum secure messaging
 type ParsVM struct {
 	cfg       config.ParsConfig
 	storage   *storage.Node
 	messenger *messaging.Messenger
+	metrics   *metrics.Registry
 	running   bool
 }
 
-// NewParsVM creates a new ParsVM instance
-func NewParsVM(cfg config.ParsConfig) (*ParsVM, error) {
+// NewParsVM creates a new ParsVM instance using the node's crypto settings
+func NewParsVM(cfg config.ParsConfig, crypto config.CryptoConfig) (*ParsVM, error) {
 	if !cfg.Enabled {
 		return &ParsVM{cfg: cfg}, nil
 	}
@@ -31,18 +38,42 @@ func NewParsVM(cfg config.ParsConfig) (*ParsVM, error) {
 	}
 
 	// Initialize messenger
-	messenger, err := messaging.NewMessenger(cfg)
+	messenger, err := messaging.NewMessenger(cfg, crypto)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create messenger: %w", err)
 	}
+	messenger.SetStorage(storageNode)
+
+	// Sign outgoing messages with the node identity
+	identity, err := messaging.LoadOrCreateIdentity(cfg.IdentityFile, crypto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load node identity: %w", err)
+	}
+	signer, err := messaging.NewSoftwareSigner(identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+	messenger.SetSigner(signer)
+
+	registry := metrics.NewRegistry()
+	if err := errors.Join(messenger.RegisterMetrics(registry), storageNode.RegisterMetrics(registry)); err != nil {
+		return nil, fmt.Errorf("failed to register metrics: %w", err)
+	}
 
 	return &ParsVM{
 		cfg:       cfg,
 		storage:   storageNode,
 		messenger: messenger,
+		metrics:   registry,
 	}, nil
 }
 
+// Metrics returns the VM's metrics registry, nil when messaging is
+// disabled
+func (p *ParsVM) Metrics() *metrics.Registry {
+	return p.metrics
+}
+
 // Name returns the VM name
 func (p *ParsVM) Name() string {
 	return "pars"
@@ -72,18 +103,26 @@ func (p *ParsVM) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the ParsVM
+// Stop stops the ParsVM in dependency order: stop accepting sends, flush
+// queued outbound messages, stop the messenger, then close storage last
+// since both of the earlier steps may still write to it
 func (p *ParsVM) Stop() error {
 	p.running = false
 
+	var err error
 	if p.messenger != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		if err = p.messenger.Flush(ctx); err != nil {
+			err = fmt.Errorf("failed to flush outbound messages: %w", err)
+		}
+		cancel()
 		p.messenger.Stop()
 	}
 	if p.storage != nil {
 		p.storage.Stop()
 	}
 
-	return nil
+	return err
 }
 
 // Health returns ParsVM health status
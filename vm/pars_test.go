@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/parsdao/node/config"
+	"github.com/parsdao/node/messaging"
+)
+
+func TestParsVMStopFlushes(t *testing.T) {
+	const queued = 500
+
+	cfg := config.Default().Pars
+	cfg.Storage.DataDir = t.TempDir()
+	cfg.IdentityFile = filepath.Join(t.TempDir(), "identity.json")
+	cfg.MaxWorkers = 1
+	p, err := NewParsVM(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	recipient := messaging.PQPrefix + strings.Repeat("cd", 32)
+	var sent atomic.Int64
+	for i := range queued {
+		msg := &messaging.Message{
+			ID:          string(rune('a' + i%26)),
+			RecipientID: recipient,
+			Ciphertext:  []byte("sealed"),
+			Signature:   []byte("signature"),
+			TTL:         24 * 60 * 60,
+		}
+		err := p.messenger.SendAsync(context.Background(), msg, func(err error) {
+			if err != nil {
+				t.Errorf("send: %v", err)
+			}
+			sent.Add(1)
+		})
+		if err != nil {
+			t.Fatalf("queue: %v", err)
+		}
+	}
+
+	if err := p.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if got := sent.Load(); got != queued {
+		t.Errorf("expected all %d queued messages sent before storage closed, got %d", queued, got)
+	}
+	if _, err := p.ReceiveMessages(context.Background(), ""); err == nil {
+		t.Error("expected ParsVM to stop accepting calls")
+	}
+}
+
+func TestParsVMSignsWithNodeIdentity(t *testing.T) {
+	cfg := config.Default().Pars
+	cfg.Storage.DataDir = t.TempDir()
+	cfg.IdentityFile = filepath.Join(t.TempDir(), "identity.json")
+	p, err := NewParsVM(cfg, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(p.messenger.Stop)
+
+	// The identity persists, so a restarted node keeps its session ID
+	id, err := messaging.LoadOrCreateIdentity(cfg.IdentityFile, config.Default().Crypto)
+	if err != nil {
+		t.Fatalf("load identity: %v", err)
+	}
+	msg := &messaging.Message{ID: "m1", SenderID: id.SessionID, RecipientID: id.SessionID}
+	if err := p.messenger.SignMessage(msg); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := msg.Verify(id.DSAPublicKey); err != nil {
+		t.Errorf("expected signature by the node identity: %v", err)
+	}
+}
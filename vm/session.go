@@ -6,37 +6,75 @@ package vm
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/luxfi/ids"
 	"github.com/luxfi/log"
 	"github.com/luxfi/session/crypto"
 	sessionvm "github.com/luxfi/session/vm"
+
+	"github.com/parsdao/node/config"
+)
+
+var (
+	ErrSessionNotOpen  = errors.New("session not open")
+	ErrTooManySessions = errors.New("too many open sessions")
 )
 
 // SessionProvider wraps the SessionVM for Pars integration
 type SessionProvider struct {
 	vm     *sessionvm.VM
+	cfg    config.SessionConfig
 	logger log.Logger
+
+	// now is the provider clock, replaceable in tests
+	now func() time.Time
+
+	mu         sync.Mutex
+	lastActive map[ids.ID]time.Time // Open sessions by last activity
+	reserved   int                  // Slots held by sessions being created
+
+	stopReaper context.CancelFunc
+	reaperDone chan struct{}
 }
 
 // NewSessionProvider creates a new SessionProvider
-func NewSessionProvider(logger log.Logger) (*SessionProvider, error) {
+func NewSessionProvider(logger log.Logger, cfg config.SessionConfig) (*SessionProvider, error) {
 	factory := &sessionvm.Factory{}
 	vm, err := factory.New(logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SessionVM: %w", err)
 	}
 
-	return &SessionProvider{
-		vm:     vm,
-		logger: logger,
-	}, nil
+	sp := &SessionProvider{
+		vm:         vm,
+		cfg:        cfg,
+		logger:     logger,
+		now:        time.Now,
+		lastActive: make(map[ids.ID]time.Time),
+	}
+
+	if timeout := sp.idleTimeout(); timeout > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		sp.stopReaper = cancel
+		sp.reaperDone = make(chan struct{})
+		go sp.reapIdleSessions(ctx, timeout/2)
+	}
+
+	return sp, nil
 }
 
 // Shutdown gracefully stops the SessionVM
 func (sp *SessionProvider) Shutdown(ctx context.Context) error {
+	if sp.stopReaper != nil {
+		sp.stopReaper()
+		<-sp.reaperDone
+	}
 	return sp.vm.Shutdown(ctx)
 }
 
@@ -56,7 +94,16 @@ func (sp *SessionProvider) CreateSession(ctx context.Context, participantIDs []s
 		participants[i] = id
 	}
 
-	return sp.vm.CreateSession(participants, publicKeys)
+	if err := sp.reserve(); err != nil {
+		return nil, err
+	}
+	session, err := sp.vm.CreateSession(participants, publicKeys)
+	if err != nil {
+		sp.release()
+		return nil, err
+	}
+	sp.track(session.ID)
+	return session, nil
 }
 
 // SendMessage sends an encrypted message through a session
@@ -71,7 +118,25 @@ func (sp *SessionProvider) SendMessage(ctx context.Context, sessionID, senderID
 		return nil, fmt.Errorf("invalid sender ID: %w", err)
 	}
 
-	return sp.vm.SendMessage(sid, sender, ciphertext, signature)
+	msg, err := sp.vm.SendMessage(sid, sender, ciphertext, signature)
+	if err != nil {
+		return nil, err
+	}
+	sp.touch(sid)
+	return msg, nil
+}
+
+// KeepAlive resets the idle timer of an open session
+func (sp *SessionProvider) KeepAlive(ctx context.Context, sessionID string) error {
+	sid, err := ids.FromString(sessionID)
+	if err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	if !sp.touch(sid) {
+		return fmt.Errorf("%w: %s", ErrSessionNotOpen, sessionID)
+	}
+	return nil
 }
 
 // GetSession retrieves session information
@@ -91,7 +156,111 @@ func (sp *SessionProvider) CloseSession(ctx context.Context, sessionID string) e
 		return fmt.Errorf("invalid session ID: %w", err)
 	}
 
-	return sp.vm.CloseSession(sid)
+	if err := sp.vm.CloseSession(sid); err != nil {
+		return err
+	}
+
+	sp.mu.Lock()
+	delete(sp.lastActive, sid)
+	sp.mu.Unlock()
+	return nil
+}
+
+// CloseIdleSessions closes every open session with no activity within
+// the configured idle timeout and returns the IDs it closed
+func (sp *SessionProvider) CloseIdleSessions(ctx context.Context) []string {
+	timeout := sp.idleTimeout()
+	if timeout <= 0 {
+		return nil
+	}
+	cutoff := sp.now().Add(-timeout)
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	var closed []string
+	for sid, last := range sp.lastActive {
+		if !last.Before(cutoff) {
+			continue
+		}
+		if err := sp.vm.CloseSession(sid); err != nil {
+			sp.logger.Warn("failed to close idle session", "session", sid, "error", err)
+			continue
+		}
+		delete(sp.lastActive, sid)
+		closed = append(closed, sid.String())
+
+		sp.logger.Info("audit: session closed for inactivity",
+			"session", sid,
+			"lastActive", last,
+			"idleTimeout", timeout,
+		)
+	}
+	return closed
+}
+
+// reapIdleSessions closes idle sessions every interval until ctx is done
+func (sp *SessionProvider) reapIdleSessions(ctx context.Context, interval time.Duration) {
+	defer close(sp.reaperDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sp.CloseIdleSessions(ctx)
+		}
+	}
+}
+
+// idleTimeout returns the configured session idle timeout
+func (sp *SessionProvider) idleTimeout() time.Duration {
+	return time.Duration(sp.cfg.IdleTimeout) * time.Second
+}
+
+// reserve claims a session slot, failing once MaxSessions are open or
+// being created. The slot passes to the session in track, or is returned
+// with release if creation fails.
+func (sp *SessionProvider) reserve() error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if max := sp.cfg.MaxSessions; max > 0 && len(sp.lastActive)+sp.reserved >= max {
+		return fmt.Errorf("%w: limit %d", ErrTooManySessions, max)
+	}
+	sp.reserved++
+	return nil
+}
+
+// release returns a slot claimed by reserve
+func (sp *SessionProvider) release() {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.reserved--
+}
+
+// track starts idle tracking for a newly opened session, taking over the
+// slot claimed by reserve
+func (sp *SessionProvider) track(sid ids.ID) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.reserved--
+	sp.lastActive[sid] = sp.now()
+}
+
+// touch records activity on an open session, reporting whether it is open
+func (sp *SessionProvider) touch(sid ids.ID) bool {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if _, ok := sp.lastActive[sid]; !ok {
+		return false
+	}
+	sp.lastActive[sid] = sp.now()
+	return true
 }
 
 // Health returns the health status of the SessionVM
@@ -119,14 +288,20 @@ func (sp *SessionProvider) CreateSecureSession(ctx context.Context, localIdentit
 	localKEMPubHex := hex.EncodeToString(localIdentity.KEMPublicKey)
 	remoteKEMPubHex := hex.EncodeToString(remoteKEMPublicKey)
 
+	if err := sp.reserve(); err != nil {
+		return nil, err
+	}
+
 	// Create session
 	session, err := sp.vm.CreateSession(
 		[]ids.ID{}, // Will be populated when we have full participant IDs
 		[][]byte{localIdentity.KEMPublicKey, remoteKEMPublicKey},
 	)
 	if err != nil {
+		sp.release()
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	sp.track(session.ID)
 
 	return &SecureSession{
 		SessionID:          session.ID.String(),
@@ -156,7 +331,28 @@ func (ss *SecureSession) DecryptMessage(ciphertext []byte) ([]byte, error) {
 	return ss.LocalIdentity.DecryptFrom(ciphertext)
 }
 
-// SignMessage signs a message with the local identity
+// SignMessage returns a detached ML-DSA signature over message, bound to
+// this session by signingContext so it cannot be replayed elsewhere
 func (ss *SecureSession) SignMessage(message []byte) ([]byte, error) {
-	return ss.LocalIdentity.SignMessage(message)
+	return crypto.Sign(ss.LocalIdentity.DSASecretKey, signingContext(ss.SessionID, message))
+}
+
+// VerifyMessage checks a SignMessage signature from the holder of
+// dsaPublicKey against this session's context
+func (ss *SecureSession) VerifyMessage(dsaPublicKey, message, signature []byte) bool {
+	return crypto.Verify(dsaPublicKey, signingContext(ss.SessionID, message), signature)
+}
+
+// messageSigningDomain separates message signatures from any other use of
+// the identity's DSA key
+const messageSigningDomain = "pars-msg-v1"
+
+// signingContext returns domain || len(sessionID) || sessionID || message.
+// The length prefix keeps session ID and message boundaries unambiguous.
+func signingContext(sessionID string, message []byte) []byte {
+	buf := make([]byte, 0, len(messageSigningDomain)+2+len(sessionID)+len(message))
+	buf = append(buf, messageSigningDomain...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(sessionID)))
+	buf = append(buf, sessionID...)
+	return append(buf, message...)
 }
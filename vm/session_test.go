@@ -0,0 +1,202 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ids"
+	"github.com/luxfi/log"
+	"github.com/luxfi/session/crypto"
+	sessionvm "github.com/luxfi/session/vm"
+
+	"github.com/parsdao/node/config"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestProvider(t *testing.T, cfg config.SessionConfig) (*SessionProvider, *fakeClock) {
+	t.Helper()
+
+	sp, err := NewSessionProvider(log.NewNoOpLogger(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = sp.Shutdown(context.Background()) })
+
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	sp.now = clock.Now
+	return sp, clock
+}
+
+func TestCloseIdleSessions(t *testing.T) {
+	ctx := context.Background()
+	sp, clock := newTestProvider(t, config.SessionConfig{IdleTimeout: 60})
+
+	alice := ids.GenerateTestID().String()
+	bob := ids.GenerateTestID().String()
+
+	idle, err := sp.CreateSession(ctx, []string{alice, bob}, nil)
+	if err != nil {
+		t.Fatalf("create idle session: %v", err)
+	}
+	active, err := sp.CreateSession(ctx, []string{alice, bob}, nil)
+	if err != nil {
+		t.Fatalf("create active session: %v", err)
+	}
+
+	clock.Advance(40 * time.Second)
+	if _, err := sp.SendMessage(ctx, active.ID.String(), alice, []byte("ct"), []byte("sig")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	closed := sp.CloseIdleSessions(ctx)
+	if len(closed) != 1 || closed[0] != idle.ID.String() {
+		t.Fatalf("expected only idle session closed, got %v", closed)
+	}
+
+	got, err := sp.GetSession(ctx, idle.ID.String())
+	if err != nil {
+		t.Fatalf("get idle session: %v", err)
+	}
+	if got.Status != sessionvm.SessionClosed {
+		t.Errorf("expected idle session closed, got %s", got.Status)
+	}
+
+	got, err = sp.GetSession(ctx, active.ID.String())
+	if err != nil {
+		t.Fatalf("get active session: %v", err)
+	}
+	if got.Status != sessionvm.SessionActive {
+		t.Errorf("expected active session open, got %s", got.Status)
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	ctx := context.Background()
+	sp, clock := newTestProvider(t, config.SessionConfig{IdleTimeout: 60})
+
+	session, err := sp.CreateSession(ctx, []string{ids.GenerateTestID().String()}, nil)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	clock.Advance(50 * time.Second)
+	if err := sp.KeepAlive(ctx, session.ID.String()); err != nil {
+		t.Fatalf("keep alive: %v", err)
+	}
+
+	clock.Advance(50 * time.Second)
+	if closed := sp.CloseIdleSessions(ctx); len(closed) != 0 {
+		t.Errorf("expected kept-alive session to stay open, closed %v", closed)
+	}
+
+	clock.Advance(61 * time.Second)
+	if closed := sp.CloseIdleSessions(ctx); len(closed) != 1 {
+		t.Errorf("expected session closed after timeout, got %v", closed)
+	}
+
+	if err := sp.KeepAlive(ctx, session.ID.String()); !errors.Is(err, ErrSessionNotOpen) {
+		t.Errorf("expected ErrSessionNotOpen after close, got %v", err)
+	}
+}
+
+func TestIdleTimeoutDisabled(t *testing.T) {
+	ctx := context.Background()
+	sp, clock := newTestProvider(t, config.SessionConfig{})
+
+	if _, err := sp.CreateSession(ctx, []string{ids.GenerateTestID().String()}, nil); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	clock.Advance(365 * 24 * time.Hour)
+	if closed := sp.CloseIdleSessions(ctx); len(closed) != 0 {
+		t.Errorf("expected no sessions closed with timeout disabled, got %v", closed)
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	ctx := context.Background()
+	sp, clock := newTestProvider(t, config.SessionConfig{MaxSessions: 2, IdleTimeout: 60})
+
+	participants := []string{ids.GenerateTestID().String()}
+	first, err := sp.CreateSession(ctx, participants, nil)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if _, err := sp.CreateSession(ctx, participants, nil); err != nil {
+		t.Fatalf("create session at limit: %v", err)
+	}
+	if _, err := sp.CreateSession(ctx, participants, nil); !errors.Is(err, ErrTooManySessions) {
+		t.Fatalf("expected ErrTooManySessions past limit, got %v", err)
+	}
+
+	// Closing a session frees its slot
+	if err := sp.CloseSession(ctx, first.ID.String()); err != nil {
+		t.Fatalf("close session: %v", err)
+	}
+	if _, err := sp.CreateSession(ctx, participants, nil); err != nil {
+		t.Fatalf("expected slot freed by close, got %v", err)
+	}
+
+	// So does closing idle sessions
+	clock.Advance(61 * time.Second)
+	if closed := sp.CloseIdleSessions(ctx); len(closed) != 2 {
+		t.Fatalf("expected both sessions closed as idle, got %v", closed)
+	}
+	if _, err := sp.CreateSession(ctx, participants, nil); err != nil {
+		t.Errorf("expected slots freed by idle close, got %v", err)
+	}
+}
+
+func TestSignMessageDomainSeparation(t *testing.T) {
+	ctx := context.Background()
+	sp, _ := newTestProvider(t, config.SessionConfig{})
+
+	alice, err := crypto.GenerateIdentity()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bob, err := crypto.GenerateIdentity()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first, err := sp.CreateSecureSession(ctx, alice, bob.KEMPublicKey)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	second, err := sp.CreateSecureSession(ctx, alice, bob.KEMPublicKey)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	msg := []byte("ciphertext")
+	sig, err := first.SignMessage(msg)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	if !first.VerifyMessage(alice.DSAPublicKey, msg, sig) {
+		t.Error("expected signature valid in its own session")
+	}
+	if second.VerifyMessage(alice.DSAPublicKey, msg, sig) {
+		t.Error("expected signature rejected in another session")
+	}
+	if crypto.Verify(alice.DSAPublicKey, msg, sig) {
+		t.Error("expected signature rejected over the raw message")
+	}
+}
@@ -0,0 +1,141 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/ids"
+
+	"github.com/parsdao/node/config"
+)
+
+var (
+	ErrWarpDisabled    = errors.New("warp is disabled")
+	ErrChainNotAllowed = errors.New("destination chain not allowed")
+)
+
+// Client sends Warp messages to other Lux chains
+type Client struct {
+	cfg config.WarpConfig
+
+	// allowed is swapped atomically so the allowlist can be
+	// replaced at runtime without blocking in-flight sends
+	allowed atomic.Pointer[allowlist]
+
+	submitter    submitter
+	sent         *sendTracker
+	retryBackoff time.Duration
+
+	prober  prober
+	breaker *circuitBreaker
+}
+
+// allowlist is an immutable set of destination chains.
+// An empty allowlist permits every chain.
+type allowlist struct {
+	chains []string
+	set    map[ids.ID]struct{}
+}
+
+// NewClient creates a new Warp client
+func NewClient(cfg config.WarpConfig) (*Client, error) {
+	c := &Client{
+		cfg:          cfg,
+		submitter:    localSubmitter{},
+		sent:         newSendTracker(),
+		retryBackoff: 500 * time.Millisecond,
+		prober:       newHTTPProber(cfg.LuxEndpoint),
+		breaker:      newCircuitBreaker(cfg.Reconnect),
+	}
+	if err := c.SetAllowedChains(cfg.AllowedChains); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetAllowedChains atomically replaces the destination chain allowlist.
+// Every entry must be a valid chain ID; on error the current list is kept.
+func (c *Client) SetAllowedChains(chains []string) error {
+	list, err := newAllowlist(chains)
+	if err != nil {
+		return err
+	}
+	c.allowed.Store(list)
+	return nil
+}
+
+// AllowedChains returns the current destination chain allowlist
+func (c *Client) AllowedChains() []string {
+	return append([]string(nil), c.allowed.Load().chains...)
+}
+
+// SendMessage sends a payload to the destination chain and returns the
+// Warp message ID. Sends are idempotent per idempotencyKey (derived with
+// IdempotencyKey when empty): repeating an already-submitted send returns
+// the original message ID without submitting again. Transient failures
+// are retried. Sends fail fast with ErrDisconnected while the endpoint is
+// unreachable.
+func (c *Client) SendMessage(ctx context.Context, chainID string, payload []byte, idempotencyKey string) (ids.ID, error) {
+	if !c.cfg.Enabled {
+		return ids.Empty, ErrWarpDisabled
+	}
+
+	id, err := ids.FromString(chainID)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("invalid chain ID %s: %w", chainID, err)
+	}
+	if !c.allowed.Load().permits(id) {
+		return ids.Empty, fmt.Errorf("%w: %s", ErrChainNotAllowed, chainID)
+	}
+	if !c.Connected() {
+		return ids.Empty, fmt.Errorf("%w: %s", ErrDisconnected, c.cfg.LuxEndpoint)
+	}
+
+	if idempotencyKey == "" {
+		idempotencyKey = IdempotencyKey(chainID, payload)
+	}
+
+	p, existing := c.sent.claim(idempotencyKey)
+	if existing {
+		select {
+		case <-p.done:
+			return p.id, p.err
+		case <-ctx.Done():
+			return ids.Empty, ctx.Err()
+		}
+	}
+
+	msgID, err := submitWithRetry(ctx, c.submitter, id, payload, c.retryBackoff)
+	c.sent.finish(idempotencyKey, p, msgID, err)
+	return msgID, err
+}
+
+func newAllowlist(chains []string) (*allowlist, error) {
+	list := &allowlist{
+		chains: make([]string, 0, len(chains)),
+		set:    make(map[ids.ID]struct{}, len(chains)),
+	}
+	for _, chain := range chains {
+		id, err := ids.FromString(chain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain ID %s: %w", chain, err)
+		}
+		if _, ok := list.set[id]; ok {
+			continue
+		}
+		list.set[id] = struct{}{}
+		list.chains = append(list.chains, chain)
+	}
+	return list, nil
+}
+
+func (l *allowlist) permits(id ids.ID) bool {
+	if len(l.set) == 0 {
+		return true
+	}
+	_, ok := l.set[id]
+	return ok
+}
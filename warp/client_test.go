@@ -0,0 +1,225 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ids"
+
+	"github.com/parsdao/node/config"
+)
+
+var (
+	chainA = ids.ID{'a'}.String()
+	chainB = ids.ID{'b'}.String()
+)
+
+func TestSetAllowedChains(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := NewClient(config.WarpConfig{
+		Enabled:       true,
+		AllowedChains: []string{chainA},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.SendMessage(ctx, chainB, []byte("payload"), ""); !errors.Is(err, ErrChainNotAllowed) {
+		t.Fatalf("expected ErrChainNotAllowed before update, got %v", err)
+	}
+
+	if err := client.SetAllowedChains([]string{chainB}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Newly added chain is accepted immediately
+	if _, err := client.SendMessage(ctx, chainB, []byte("payload"), ""); err != nil {
+		t.Errorf("expected chain B allowed after update, got %v", err)
+	}
+
+	// Removed chain is rejected immediately
+	if _, err := client.SendMessage(ctx, chainA, []byte("payload"), ""); !errors.Is(err, ErrChainNotAllowed) {
+		t.Errorf("expected ErrChainNotAllowed for removed chain, got %v", err)
+	}
+}
+
+func TestSetAllowedChainsInvalid(t *testing.T) {
+	client, err := NewClient(config.WarpConfig{
+		Enabled:       true,
+		AllowedChains: []string{chainA},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := client.SetAllowedChains([]string{chainB, "not-a-chain"}); err == nil {
+		t.Fatal("expected error for invalid chain ID")
+	}
+
+	// A failed update leaves the previous list in place
+	got := client.AllowedChains()
+	if len(got) != 1 || got[0] != chainA {
+		t.Errorf("expected allowlist unchanged, got %v", got)
+	}
+}
+
+func TestEmptyAllowlistPermitsAll(t *testing.T) {
+	client, err := NewClient(config.WarpConfig{Enabled: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.SendMessage(context.Background(), chainA, nil, ""); err != nil {
+		t.Errorf("expected empty allowlist to permit chain, got %v", err)
+	}
+}
+
+func TestSendMessageDisabled(t *testing.T) {
+	client, err := NewClient(config.WarpConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.SendMessage(context.Background(), chainA, nil, ""); !errors.Is(err, ErrWarpDisabled) {
+		t.Errorf("expected ErrWarpDisabled, got %v", err)
+	}
+}
+
+// fakeSubmitter counts submissions and fails the first failures with a
+// transient error
+type fakeSubmitter struct {
+	mu       sync.Mutex
+	calls    int
+	failures int
+}
+
+func (f *fakeSubmitter) Submit(ctx context.Context, chainID ids.ID, payload []byte) (ids.ID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return ids.Empty, fmt.Errorf("%w: endpoint unavailable", ErrTransient)
+	}
+	return ids.GenerateTestID(), nil
+}
+
+func newTestClient(t *testing.T, sub submitter) *Client {
+	t.Helper()
+	client, err := NewClient(config.WarpConfig{Enabled: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.submitter = sub
+	client.retryBackoff = time.Millisecond
+	return client
+}
+
+func TestSendMessageIdempotent(t *testing.T) {
+	ctx := context.Background()
+	sub := &fakeSubmitter{}
+	client := newTestClient(t, sub)
+
+	first, err := client.SendMessage(ctx, chainA, []byte("payload"), "transfer-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := client.SendMessage(ctx, chainA, []byte("payload"), "transfer-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sub.calls != 1 {
+		t.Errorf("expected one submission, got %d", sub.calls)
+	}
+	if first != second {
+		t.Errorf("expected retry to return %s, got %s", first, second)
+	}
+
+	// A different key is a new message even with the same payload
+	if _, err := client.SendMessage(ctx, chainA, []byte("payload"), "transfer-2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.calls != 2 {
+		t.Errorf("expected a second submission for a new key, got %d", sub.calls)
+	}
+}
+
+func TestSendTrackerKeepsInFlight(t *testing.T) {
+	tr := newSendTracker()
+	live, existing := tr.claim("live")
+	if existing {
+		t.Fatal("expected a new send")
+	}
+
+	// Completing more than the bound evicts only completed sends
+	for i := range maxTrackedSends + 1 {
+		key := fmt.Sprint("done-", i)
+		p, _ := tr.claim(key)
+		tr.finish(key, p, ids.ID{1}, nil)
+	}
+	if p, existing := tr.claim("live"); !existing || p != live {
+		t.Error("expected the in-flight send kept past eviction")
+	}
+	if _, existing := tr.claim("done-0"); existing {
+		t.Error("expected the oldest completed send evicted")
+	}
+	if _, existing := tr.claim(fmt.Sprint("done-", maxTrackedSends)); !existing {
+		t.Error("expected the newest completed send kept")
+	}
+
+	// A failed send is forgotten so it can be retried
+	tr.finish("live", live, ids.Empty, errors.New("boom"))
+	if _, existing := tr.claim("live"); existing {
+		t.Error("expected a failed send to be retryable")
+	}
+}
+
+func TestSendMessageConcurrentSameKey(t *testing.T) {
+	sub := &fakeSubmitter{}
+	client := newTestClient(t, sub)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.SendMessage(context.Background(), chainA, []byte("payload"), ""); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sub.calls != 1 {
+		t.Errorf("expected one submission for concurrent sends, got %d", sub.calls)
+	}
+}
+
+func TestSendMessageRetriesTransient(t *testing.T) {
+	ctx := context.Background()
+
+	sub := &fakeSubmitter{failures: maxSendAttempts - 1}
+	client := newTestClient(t, sub)
+	if _, err := client.SendMessage(ctx, chainA, []byte("payload"), ""); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if sub.calls != maxSendAttempts {
+		t.Errorf("expected %d attempts, got %d", maxSendAttempts, sub.calls)
+	}
+
+	sub = &fakeSubmitter{failures: maxSendAttempts}
+	client = newTestClient(t, sub)
+	if _, err := client.SendMessage(ctx, chainA, []byte("payload"), ""); !errors.Is(err, ErrTransient) {
+		t.Fatalf("expected ErrTransient after exhausting retries, got %v", err)
+	}
+
+	// A failed send is not remembered, so the key can be retried later
+	if _, err := client.SendMessage(ctx, chainA, []byte("payload"), ""); err != nil {
+		t.Errorf("expected later retry to succeed, got %v", err)
+	}
+}
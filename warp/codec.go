@@ -0,0 +1,126 @@
+// Package warp provides payload encoding for Pars cross-chain Warp messages
+package warp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// CodecVersion is the current payload codec version
+const CodecVersion uint8 = 1
+
+var (
+	ErrInvalidVersion = errors.New("invalid warp payload version")
+	ErrInvalidPayload = errors.New("invalid warp payload")
+)
+
+// PayloadType identifies the kind of payload carried in a Warp message
+type PayloadType uint16
+
+const (
+	TypeStakingEvent PayloadType = 1 // X-Chain staking bridge events
+	TypeFeeTransfer  PayloadType = 2 // Fee collection to the X-Chain recipient
+)
+
+// Payload is a typed Warp message payload
+type Payload interface {
+	PayloadType() PayloadType
+}
+
+// Envelope is the versioned wire form of every Warp payload.
+// Payloads of unknown type or newer version decode into an Envelope
+// so callers can inspect or forward them without losing data.
+type Envelope struct {
+	Version uint8           `json:"version"`
+	Type    PayloadType     `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// PayloadType returns the envelope's payload type
+func (e *Envelope) PayloadType() PayloadType {
+	return e.Type
+}
+
+// StakingAction is the kind of staking change
+type StakingAction string
+
+const (
+	ActionStake   StakingAction = "stake"
+	ActionUnstake StakingAction = "unstake"
+)
+
+// StakingEvent is a PARS staking change relayed from the X-Chain
+type StakingEvent struct {
+	Action    StakingAction `json:"action"`
+	NodeID    string        `json:"nodeId"`
+	Staker    string        `json:"staker"` // X-Chain address
+	Amount    uint64        `json:"amount"`
+	TxID      string        `json:"txId"`
+	Timestamp int64         `json:"timestamp"` // Unix seconds
+}
+
+// PayloadType returns TypeStakingEvent
+func (*StakingEvent) PayloadType() PayloadType {
+	return TypeStakingEvent
+}
+
+// FeeTransfer moves collected fees to the X-Chain fee recipient
+type FeeTransfer struct {
+	Recipient string `json:"recipient"` // X-Chain address
+	Amount    uint64 `json:"amount"`
+	Epoch     uint64 `json:"epoch"`
+}
+
+// PayloadType returns TypeFeeTransfer
+func (*FeeTransfer) PayloadType() PayloadType {
+	return TypeFeeTransfer
+}
+
+// Encode serializes a payload into a versioned envelope
+func Encode(p Payload) ([]byte, error) {
+	if env, ok := p.(*Envelope); ok {
+		return json.Marshal(env)
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	return json.Marshal(&Envelope{
+		Version: CodecVersion,
+		Type:    p.PayloadType(),
+		Payload: body,
+	})
+}
+
+// Decode parses a versioned envelope into its typed payload.
+// Unknown types and newer versions are returned as *Envelope.
+func Decode(data []byte) (Payload, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if env.Version == 0 {
+		return nil, ErrInvalidVersion
+	}
+	if env.Version > CodecVersion {
+		return &env, nil
+	}
+
+	var p Payload
+	switch env.Type {
+	case TypeStakingEvent:
+		p = &StakingEvent{}
+	case TypeFeeTransfer:
+		p = &FeeTransfer{}
+	default:
+		return &env, nil
+	}
+
+	if err := json.Unmarshal(env.Payload, p); err != nil {
+		return nil, fmt.Errorf("%w: type %d: %v", ErrInvalidPayload, env.Type, err)
+	}
+	return p, nil
+}
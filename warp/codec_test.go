@@ -0,0 +1,98 @@
+package warp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	tests := []Payload{
+		&StakingEvent{
+			Action:    ActionStake,
+			NodeID:    "NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg",
+			Staker:    "X-pars1qyqszqgpqyqszqgpqyqszqgpqyqszqgp",
+			Amount:    15000,
+			TxID:      "2oYMBNV4eNHyqk2fjjV5nVQLDbtmNJzq5s3qs3Lo6ftnC6FByM",
+			Timestamp: 1700000000,
+		},
+		&FeeTransfer{
+			Recipient: "X-pars1qyqszqgpqyqszqgpqyqszqgpqyqszqgp",
+			Amount:    42,
+			Epoch:     7,
+		},
+	}
+
+	for _, want := range tests {
+		data, err := Encode(want)
+		if err != nil {
+			t.Fatalf("encode %T: %v", want, err)
+		}
+
+		got, err := Decode(data)
+		if err != nil {
+			t.Fatalf("decode %T: %v", want, err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip mismatch: got %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestDecodeUnknownType(t *testing.T) {
+	data := []byte(`{"version":1,"type":999,"payload":{"foo":"bar"}}`)
+
+	p, err := Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	env, ok := p.(*Envelope)
+	if !ok {
+		t.Fatalf("expected *Envelope, got %T", p)
+	}
+	if env.Type != 999 {
+		t.Errorf("expected type 999, got %d", env.Type)
+	}
+	if string(env.Payload) != `{"foo":"bar"}` {
+		t.Errorf("expected raw payload preserved, got %s", env.Payload)
+	}
+
+	// Re-encoding an unknown envelope is lossless
+	out, err := Encode(env)
+	if err != nil {
+		t.Fatalf("re-encode: %v", err)
+	}
+	if string(out) != string(data) {
+		t.Errorf("expected %s, got %s", data, out)
+	}
+}
+
+func TestDecodeNewerVersion(t *testing.T) {
+	p, err := Decode([]byte(`{"version":2,"type":1,"payload":{"action":"stake"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := p.(*Envelope); !ok {
+		t.Errorf("expected *Envelope for newer version, got %T", p)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"not json", "garbage", ErrInvalidPayload},
+		{"zero version", `{"version":0,"type":1,"payload":{}}`, ErrInvalidVersion},
+		{"bad payload", `{"version":1,"type":1,"payload":{"amount":"lots"}}`, ErrInvalidPayload},
+	}
+
+	for _, tt := range tests {
+		if _, err := Decode([]byte(tt.data)); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
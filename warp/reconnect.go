@@ -0,0 +1,156 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+// Probe timing used when the config leaves it unset
+const (
+	defaultProbeInterval  = 10 * time.Second
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+)
+
+// ErrDisconnected is returned while the circuit breaker holds LuxEndpoint
+// unreachable
+var ErrDisconnected = errors.New("warp endpoint unreachable")
+
+// prober checks whether the Lux endpoint is reachable
+type prober interface {
+	Probe(ctx context.Context) error
+}
+
+// httpProber probes the node health route of the Lux endpoint
+type httpProber struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPProber(endpoint string) httpProber {
+	return httpProber{
+		url:    strings.TrimSuffix(endpoint, "/") + "/ext/health",
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (p httpProber) Probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	// 503 means some luxd check is failing, but the endpoint is reachable
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return fmt.Errorf("health probe: %s", resp.Status)
+	}
+	return nil
+}
+
+// circuitBreaker tracks endpoint reachability. It opens after
+// FailureThreshold consecutive failed probes and then retries with a
+// doubling backoff until a probe succeeds. It starts closed (connected).
+type circuitBreaker struct {
+	cfg config.ReconnectConfig
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	backoff  time.Duration
+}
+
+func newCircuitBreaker(cfg config.ReconnectConfig) *circuitBreaker {
+	b := &circuitBreaker{cfg: cfg}
+	b.backoff = b.initialBackoff()
+	return b
+}
+
+// connected reports whether the breaker is closed
+func (b *circuitBreaker) connected() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.open
+}
+
+// record updates the breaker with a probe result and returns the delay
+// before the next probe
+func (b *circuitBreaker) record(err error) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.open = false
+		b.backoff = b.initialBackoff()
+		return b.probeInterval()
+	}
+
+	b.failures++
+	if b.failures < b.cfg.FailureThreshold {
+		return b.probeInterval()
+	}
+	if b.open {
+		b.backoff = min(b.backoff*2, b.maxBackoff())
+	}
+	b.open = true
+	return b.backoff
+}
+
+func (b *circuitBreaker) probeInterval() time.Duration {
+	return orDefault(ms(b.cfg.ProbeIntervalMs), defaultProbeInterval)
+}
+
+func (b *circuitBreaker) initialBackoff() time.Duration {
+	return min(orDefault(ms(b.cfg.InitialBackoffMs), defaultInitialBackoff), b.maxBackoff())
+}
+
+func (b *circuitBreaker) maxBackoff() time.Duration {
+	return orDefault(ms(b.cfg.MaxBackoffMs), defaultMaxBackoff)
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// Start probes LuxEndpoint in the background until ctx is done, keeping
+// Connected up to date
+func (c *Client) Start(ctx context.Context) {
+	go func() {
+		for {
+			delay := c.probe(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+}
+
+// Connected reports whether LuxEndpoint is considered reachable
+func (c *Client) Connected() bool {
+	return c.breaker.connected()
+}
+
+// probe runs one health probe and returns the delay before the next
+func (c *Client) probe(ctx context.Context) time.Duration {
+	return c.breaker.record(c.prober.Probe(ctx))
+}
+
+func ms(n int64) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
@@ -0,0 +1,101 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/parsdao/node/config"
+)
+
+// fakeProber fails while down is set
+type fakeProber struct {
+	down bool
+}
+
+func (p *fakeProber) Probe(ctx context.Context) error {
+	if p.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewClient(config.WarpConfig{
+		Enabled: true,
+		Reconnect: config.ReconnectConfig{
+			ProbeIntervalMs:  1000,
+			FailureThreshold: 2,
+			InitialBackoffMs: 100,
+			MaxBackoffMs:     300,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := &fakeProber{down: true}
+	client.prober = p
+
+	// Below the threshold the client stays connected and probes normally
+	if d := client.probe(ctx); d != time.Second || !client.Connected() {
+		t.Errorf("expected connected with 1s probe interval, got %v connected=%v", d, client.Connected())
+	}
+
+	for i, want := range []time.Duration{100, 200, 300, 300} {
+		if d := client.probe(ctx); d != want*time.Millisecond {
+			t.Errorf("failure %d: expected backoff %v, got %v", i+2, want*time.Millisecond, d)
+		}
+		if client.Connected() {
+			t.Errorf("failure %d: expected disconnected", i+2)
+		}
+	}
+	if _, err := client.SendMessage(ctx, chainA, []byte("payload"), ""); !errors.Is(err, ErrDisconnected) {
+		t.Errorf("expected ErrDisconnected while down, got %v", err)
+	}
+
+	p.down = false
+	if d := client.probe(ctx); d != time.Second || !client.Connected() {
+		t.Errorf("expected recovery to 1s probe interval, got %v connected=%v", d, client.Connected())
+	}
+	if _, err := client.SendMessage(ctx, chainA, []byte("payload"), ""); err != nil {
+		t.Errorf("expected send after recovery, got %v", err)
+	}
+
+	// The backoff restarts from the initial delay after recovery
+	p.down = true
+	client.probe(ctx)
+	if d := client.probe(ctx); d != 100*time.Millisecond {
+		t.Errorf("expected backoff reset to 100ms, got %v", d)
+	}
+}
+
+func TestHTTPProber(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ext/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+
+	p := newHTTPProber(srv.URL + "/")
+	for _, status = range []int{http.StatusOK, http.StatusServiceUnavailable} {
+		if err := p.Probe(context.Background()); err != nil {
+			t.Errorf("status %d: expected reachable, got %v", status, err)
+		}
+	}
+	status = http.StatusBadGateway
+	if err := p.Probe(context.Background()); err == nil {
+		t.Error("expected error for bad gateway")
+	}
+
+	srv.Close()
+	if err := p.Probe(context.Background()); err == nil {
+		t.Error("expected error for unreachable endpoint")
+	}
+}
@@ -0,0 +1,132 @@
+package warp
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/luxfi/ids"
+)
+
+const (
+	// maxSendAttempts bounds retries of a transiently failing submission
+	maxSendAttempts = 3
+
+	// maxTrackedSends bounds how many idempotency keys are remembered
+	maxTrackedSends = 4096
+)
+
+// ErrTransient marks a submission failure that is safe to retry
+var ErrTransient = errors.New("transient warp error")
+
+// submitter delivers a Warp message to the destination chain and returns
+// its message ID
+type submitter interface {
+	Submit(ctx context.Context, chainID ids.ID, payload []byte) (ids.ID, error)
+}
+
+// localSubmitter derives the message ID without submitting anything
+type localSubmitter struct{}
+
+func (localSubmitter) Submit(ctx context.Context, chainID ids.ID, payload []byte) (ids.ID, error) {
+	// TODO: Submit the signed Warp message via cfg.LuxEndpoint
+	h := sha256.New()
+	h.Write(chainID[:])
+	h.Write(payload)
+	return ids.ID(h.Sum(nil)), nil
+}
+
+// IdempotencyKey derives the default idempotency key for payload sent to
+// chainID. Resending identical bytes to the same chain is treated as a
+// retry; callers that intend a second message must supply their own key.
+func IdempotencyKey(chainID string, payload []byte) string {
+	h := sha256.New()
+	h.Write([]byte(chainID))
+	h.Write([]byte{0})
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// pendingSend is a submission for one idempotency key. done is closed once
+// id and err are set.
+type pendingSend struct {
+	done chan struct{}
+	id   ids.ID
+	err  error
+}
+
+// sendTracker remembers submissions by idempotency key. In-flight sends
+// are always kept; of the completed ones, the oldest are evicted beyond
+// maxTrackedSends.
+type sendTracker struct {
+	mu        sync.Mutex
+	sends     map[string]*pendingSend
+	completed *list.List // Keys of successful sends, oldest first
+}
+
+func newSendTracker() *sendTracker {
+	return &sendTracker{sends: make(map[string]*pendingSend), completed: list.New()}
+}
+
+// claim returns the existing send for key, or registers a new one that
+// the caller must complete with finish
+func (t *sendTracker) claim(key string) (*pendingSend, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p, ok := t.sends[key]; ok {
+		return p, true
+	}
+	p := &pendingSend{done: make(chan struct{})}
+	t.sends[key] = p
+	return p, false
+}
+
+// finish records the outcome of p. Failed sends are forgotten so a later
+// call with the same key can try again.
+func (t *sendTracker) finish(key string, p *pendingSend, id ids.ID, err error) {
+	p.id, p.err = id, err
+
+	t.mu.Lock()
+	if t.sends[key] == p {
+		if err != nil {
+			delete(t.sends, key)
+		} else {
+			t.completed.PushBack(key)
+			for t.completed.Len() > maxTrackedSends {
+				delete(t.sends, t.completed.Remove(t.completed.Front()).(string))
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	close(p.done)
+}
+
+// submitWithRetry retries transient failures with a doubling backoff
+func submitWithRetry(ctx context.Context, s submitter, chainID ids.ID, payload []byte, backoff time.Duration) (ids.ID, error) {
+	var err error
+	for attempt := 1; attempt <= maxSendAttempts; attempt++ {
+		var id ids.ID
+		id, err = s.Submit(ctx, chainID, payload)
+		if err == nil || !errors.Is(err, ErrTransient) {
+			return id, err
+		}
+		if attempt == maxSendAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ids.Empty, ctx.Err()
+		}
+	}
+	return ids.Empty, fmt.Errorf("warp submit failed after %d attempts: %w", maxSendAttempts, err)
+}